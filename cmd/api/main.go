@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/rezbow/tickr/internal/accesscodes"
 	"github.com/rezbow/tickr/internal/auth"
+	"github.com/rezbow/tickr/internal/checkin"
 	"github.com/rezbow/tickr/internal/database"
+	"github.com/rezbow/tickr/internal/discounts"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/events"
+	"github.com/rezbow/tickr/internal/idempotency"
+	"github.com/rezbow/tickr/internal/ledger"
+	"github.com/rezbow/tickr/internal/mail"
+	"github.com/rezbow/tickr/internal/orders"
+	"github.com/rezbow/tickr/internal/passes"
 	"github.com/rezbow/tickr/internal/payment"
+	"github.com/rezbow/tickr/internal/receipts"
+	"github.com/rezbow/tickr/internal/resale"
 	"github.com/rezbow/tickr/internal/tickets"
+	"github.com/rezbow/tickr/internal/transfers"
 	"github.com/rezbow/tickr/internal/users"
+	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
+	"github.com/rezbow/tickr/internal/waitlist"
 )
 
 func main() {
@@ -24,25 +40,54 @@ func main() {
 	if dsn == "" {
 		panic("missing DB_URL env")
 	}
+	if _, err := utils.CursorSigningSecret(); err != nil {
+		panic(err.Error())
+	}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	db := database.SetupDatabase(dsn)
 	userService := users.NewUserService(db, logger)
 	eventsService := events.NewEventsService(db, logger)
 	ticketService := tickets.NewTicketsService(db, logger)
-	paymentService := payment.NewPaymentService(db, logger)
+	ordersService := orders.NewOrdersService(db, logger)
+	paymentService := payment.NewPaymentService(db, logger, payment.NewFakeGateway())
+	passesService := passes.NewPassesService(db, logger)
+	checkinService := checkin.NewCheckinService(db, logger)
+	transfersService := transfers.NewTransfersService(db, logger, mail.SenderFromEnv(logger))
+	waitlistService := waitlist.NewWaitlistService(db, logger)
+	resaleService := resale.NewResaleService(db, logger, ordersService.HoldWindow())
+	discountsService := discounts.NewDiscountsService(db, logger)
+	accessCodesService := accesscodes.NewAccessCodesService(db, logger)
+	receiptsService := receipts.NewReceiptsService(db, logger)
+	ledgerService := ledger.NewLedgerService(db, logger)
+	venuesService := venues.NewVenuesService(db, logger)
+	idempotencyService := idempotency.NewIdempotencyService(db, logger)
 	jwtService := auth.NewJWTService()
 
+	go ordersService.RunExpirySweeper(context.Background(), time.Minute)
+	go eventsService.RunCompletion(context.Background(), time.Minute)
+	go ledgerService.RunSettlement(context.Background(), time.Hour)
+	go idempotencyService.RunCleanup(context.Background(), time.Hour)
+	idempotent := idempotencyService.Middleware()
+	optionalAuth := auth.OptionalAuthMiddleware(jwtService)
+
 	engine := gin.Default()
 
 	// Public routes (no authentication required)
 	engine.POST("/auth/login", userService.LoginHandler)
 	engine.POST("/auth/refresh", userService.RefreshTokenHandler)
-	engine.POST("/users", userService.CreateUserHandler)
+	engine.POST("/users", idempotent, userService.CreateUserHandler)
 	engine.GET("/events", eventsService.GetEventsHandler)
-	engine.GET("/events/:id", eventsService.GetEventHandler)
-	engine.GET("/events/:id/tickets", ticketService.GetEventTicketsHandler)
-	engine.GET("/tickets/:id", ticketService.GetTicket)
+	engine.GET("/events/:id", optionalAuth, eventsService.GetEventHandler)
+	engine.GET("/events/:id/changes", optionalAuth, eventsService.GetEventChangesHandler)
+	engine.GET("/events/:id/tickets", optionalAuth, ticketService.GetEventTicketsHandler)
+	engine.GET("/events/:id/listings", optionalAuth, resaleService.GetEventListingsHandler)
+	engine.GET("/tickets/:id", optionalAuth, ticketService.GetTicket)
+	engine.GET("/venues", venuesService.GetVenuesHandler)
+	engine.GET("/venues/:id", venuesService.GetVenueHandler)
+	engine.GET("/checkin/manifest-key", checkinService.GetManifestKeyHandler)
+	engine.POST("/transfers/accept", transfersService.AcceptTransferHandler)
+	engine.POST("/webhooks/payments/:provider", paymentService.PaymentWebhookHandler)
 
 	// Protected routes (authentication required)
 	protected := engine.Group("/")
@@ -51,6 +96,11 @@ func main() {
 		// Auth routes
 		protected.POST("/auth/logout", userService.LogoutHandler)
 		protected.GET("/auth/profile", userService.GetProfileHandler)
+		protected.GET("/me/payments", paymentService.GetMyPaymentsHandler)
+		protected.GET("/me/passes", passesService.GetMyPassesHandler)
+		protected.GET("/me/transfers", transfersService.GetMyTransfersHandler)
+		protected.GET("/me/waitlist", waitlistService.GetMyWaitlistHandler)
+		protected.GET("/me/listings", resaleService.GetMyListingsHandler)
 
 		// User management (admin only)
 		protected.GET("/users", auth.RequireRole("admin"), userService.GetUsersHandler)
@@ -59,16 +109,71 @@ func main() {
 		protected.PUT("/users/:id", auth.RequireOwnershipOrRole("admin"), userService.UpdateUserHander)
 
 		// Event management (organizers and admins)
-		protected.POST("/events", auth.RequireRoles([]string{"organizer", "admin"}), eventsService.CreateEventHandler)
+		protected.POST("/events", auth.RequireRoles([]string{"organizer", "admin"}), idempotent, eventsService.CreateEventHandler)
+		protected.PATCH("/events/:id", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.UpdateEventHandler)
 		protected.DELETE("/events/:id", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.DeleteEventHandler)
-		protected.POST("/events/:id/tickets", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), ticketService.CreateTicketHandler)
+		protected.POST("/events/:id/publish", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.PublishEventHandler)
+		protected.POST("/events/:id/postpone", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.PostponeEventHandler)
+		protected.POST("/events/:id/cancel", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), idempotent, paymentService.CancelEventHandler)
+		protected.POST("/events/:id/tickets", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), idempotent, ticketService.CreateTicketHandler)
+		protected.GET("/events/:id/staff", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.GetEventStaffHandler)
+		protected.POST("/events/:id/staff", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.AddEventStaffHandler)
+		protected.DELETE("/events/:id/staff/:userId", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), eventsService.RemoveEventStaffHandler)
+		protected.GET("/events/:id/discounts", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), discountsService.GetEventDiscountsHandler)
+		protected.POST("/events/:id/discounts", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), discountsService.CreateDiscountHandler)
+		protected.POST("/events/:id/discounts/:discountId/disable", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin"), discountsService.DisableDiscountHandler)
+
+		// Check-in (event owners, staff and admins)
+		protected.POST("/events/:id/checkin", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin", auth.EventStaff), checkinService.CheckinHandler)
+		protected.POST("/events/:id/checkin/undo", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin", auth.EventStaff), checkinService.UndoCheckinHandler)
+		protected.GET("/events/:id/checkin/stats", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin", auth.EventStaff), checkinService.GetCheckinStatsHandler)
+		protected.GET("/events/:id/checkin-manifest", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin", auth.EventStaff), checkinService.GetManifestHandler)
+		protected.POST("/events/:id/checkin/sync", auth.RequireEntityOwnershipOrRole(db, entities.Event{}, "admin", auth.EventStaff), checkinService.SyncHandler)
+
+		// Venue management (organizers and admins)
+		protected.POST("/venues", auth.RequireRoles([]string{"organizer", "admin"}), idempotent, venuesService.CreateVenueHandler)
+		protected.PATCH("/venues/:id", auth.RequireEntityOwnershipOrRole(db, entities.Venue{}, "admin"), venuesService.UpdateVenueHandler)
+		protected.DELETE("/venues/:id", auth.RequireEntityOwnershipOrRole(db, entities.Venue{}, "admin"), venuesService.DeleteVenueHandler)
 
 		// Ticket management (organizers and admins)
+		protected.PATCH("/tickets/:id", auth.RequireEntityOwnershipOrRole(db, entities.Ticket{}, "admin"), ticketService.UpdateTicketHandler)
 		protected.DELETE("/tickets/:id", auth.RequireEntityOwnershipOrRole(db, entities.Ticket{}, "admin"), ticketService.DeleteTicket)
+		protected.GET("/tickets/:id/access-codes", auth.RequireEntityOwnershipOrRole(db, entities.Ticket{}, "admin"), accessCodesService.GetTicketAccessCodesHandler)
+		protected.POST("/tickets/:id/access-codes", auth.RequireEntityOwnershipOrRole(db, entities.Ticket{}, "admin"), accessCodesService.CreateAccessCodeHandler)
+		protected.POST("/tickets/:id/access-codes/:codeId/disable", auth.RequireEntityOwnershipOrRole(db, entities.Ticket{}, "admin"), accessCodesService.DisableAccessCodeHandler)
+
+		// Waitlist (authenticated users)
+		protected.POST("/tickets/:id/waitlist", idempotent, waitlistService.JoinWaitlistHandler)
+		protected.DELETE("/tickets/:id/waitlist", waitlistService.LeaveWaitlistHandler)
+
+		// Order management (authenticated users)
+		protected.POST("/orders", idempotent, ordersService.CreateOrderHandler)
+		protected.GET("/orders/:id", auth.RequireEntityOwnershipOrRole(db, entities.Order{}, "admin"), ordersService.GetOrderHandler)
+		protected.POST("/orders/:id/cancel", auth.RequireEntityOwnershipOrRole(db, entities.Order{}, "admin"), ordersService.CancelOrderHandler)
 
 		// Payment management (authenticated users)
-		protected.POST("/payments", paymentService.BuyTicketHandler)
-		protected.GET("/payments/:id", paymentService.GetPaymentHandler)
+		protected.POST("/payments", idempotent, paymentService.BuyTicketHandler)
+		protected.POST("/payments/quote", paymentService.QuotePaymentHandler)
+		protected.GET("/payments/:id", auth.RequireEntityOwnershipOrRole(db, entities.Payment{}, "admin"), paymentService.GetPaymentHandler)
+		protected.GET("/payments/:id/passes", auth.RequireEntityOwnershipOrRole(db, entities.Payment{}, "admin"), passesService.GetPaymentPassesHandler)
+		protected.GET("/payments/:id/receipt.pdf", auth.RequireEntityOwnershipOrRole(db, entities.Payment{}, "admin"), receiptsService.GetReceiptHandler)
+		protected.POST("/payments/:id/refund", auth.RequireRoles([]string{"organizer", "admin"}), idempotent, paymentService.RefundPaymentHandler)
+
+		// Passes (holders and admins)
+		protected.GET("/passes/:id/qr.png", auth.RequireEntityOwnershipOrRole(db, entities.Pass{}, "admin"), passesService.GetPassQRHandler)
+
+		// Transfers (holders and admins)
+		protected.POST("/transfers", idempotent, transfersService.CreateTransferHandler)
+		protected.POST("/transfers/:id/cancel", auth.RequireEntityOwnershipOrRole(db, entities.Transfer{}, "admin"), transfersService.CancelTransferHandler)
+
+		// Resale marketplace (authenticated users)
+		protected.POST("/listings", idempotent, resaleService.CreateListingHandler)
+		protected.POST("/listings/:id/cancel", auth.RequireEntityOwnershipOrRole(db, entities.Listing{}, "admin"), resaleService.CancelListingHandler)
+		protected.POST("/listings/:id/orders", idempotent, resaleService.CreateListingOrderHandler)
+
+		// Payouts (organizers and admins)
+		protected.GET("/organizer/payouts", auth.RequireRoles([]string{"organizer", "admin"}), ledgerService.GetMyPayoutsHandler)
+		protected.GET("/organizer/payouts.csv", auth.RequireRoles([]string{"organizer", "admin"}), ledgerService.ExportPayoutsHandler)
 
 	}
 
@@ -0,0 +1,78 @@
+// Command fakewebhook signs a fake payment provider event the way a real
+// provider would and delivers it to a running API, for exercising
+// POST /webhooks/payments/:provider locally.
+//
+//	go run ./cmd/fakewebhook -type payment.captured -reference fake_<uuid>
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/rezbow/tickr/internal/payment"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	url := flag.String("url", "http://localhost:8080", "base URL of the API")
+	provider := flag.String("provider", "fake", "provider name used in the webhook path and secret lookup")
+	eventType := flag.String("type", payment.WebhookPaymentCaptured, "event type")
+	reference := flag.String("reference", "", "gateway reference of the payment (Payment.ProviderRef)")
+	eventId := flag.String("id", "", "provider event id, random when empty; reuse it to test deduplication")
+	skew := flag.Duration("skew", 0, "shift the signature timestamp, e.g. -10m to test replay protection")
+	dryRun := flag.Bool("dry-run", false, "print the signed request instead of sending it")
+	flag.Parse()
+
+	if *reference == "" {
+		fmt.Fprintln(os.Stderr, "-reference is required")
+		os.Exit(2)
+	}
+	secret := payment.WebhookSecret(*provider)
+	if secret == nil {
+		fmt.Fprintf(os.Stderr, "no secret configured for provider %q\n", *provider)
+		os.Exit(2)
+	}
+	if *eventId == "" {
+		*eventId = "evt_" + uuid.NewString()
+	}
+
+	var payload payment.WebhookPayload
+	payload.ID = *eventId
+	payload.Type = *eventType
+	payload.Data.Reference = *reference
+	body, err := json.Marshal(payload)
+	if err != nil {
+		panic(err.Error())
+	}
+	signature := payment.SignWebhook(secret, time.Now().Add(*skew), body)
+
+	if *dryRun {
+		fmt.Printf("%s: %s\n%s\n", payment.WebhookSignatureHeader, signature, body)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url+"/webhooks/payments/"+*provider, bytes.NewReader(body))
+	if err != nil {
+		panic(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payment.WebhookSignatureHeader, signature)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	defer res.Body.Close()
+	response, _ := io.ReadAll(res.Body)
+	fmt.Printf("%s\n%s\n", res.Status, response)
+}
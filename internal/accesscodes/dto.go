@@ -0,0 +1,60 @@
+package accesscodes
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,64}$`)
+
+type AccessCodeCreateDTO struct {
+	Code       string     `json:"code" binding:"required"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+func (a *AccessCodeCreateDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Regex(utils.NormalizeCode(a.Code), codePattern, "code", "code must be 4 to 64 letters, digits, dashes or underscores")
+	if a.ValidUntil != nil {
+		v.Must(a.ValidUntil.After(time.Now()), "valid_until", "valid_until should be in future")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type AccessCode struct {
+	ID         uuid.UUID  `json:"id"`
+	TicketId   uuid.UUID  `json:"ticket_id"`
+	Code       string     `json:"code"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func AccessCodeEntityToAccessCode(a *entities.AccessCode) AccessCode {
+	accessCode := AccessCode{
+		ID:        a.ID,
+		TicketId:  a.TicketId,
+		Code:      a.Code,
+		Active:    a.Active,
+		CreatedAt: a.CreatedAt,
+	}
+	if a.ValidUntil.Valid {
+		accessCode.ValidUntil = &a.ValidUntil.Time
+	}
+	return accessCode
+}
+
+func AccessCodeEntitiesToAccessCodes(accessCodes []entities.AccessCode) []AccessCode {
+	result := make([]AccessCode, len(accessCodes))
+	for i := range accessCodes {
+		result[i] = AccessCodeEntityToAccessCode(&accessCodes[i])
+	}
+	return result
+}
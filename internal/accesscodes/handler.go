@@ -0,0 +1,106 @@
+package accesscodes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (service *AccessCodesService) CreateAccessCodeHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+
+	var input AccessCodeCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	accessCode := &entities.AccessCode{
+		TicketId: ticketId,
+		UserId:   userId,
+		Code:     utils.NormalizeCode(input.Code),
+		Active:   true,
+	}
+	if input.ValidUntil != nil {
+		accessCode.ValidUntil = sql.NullTime{Time: *input.ValidUntil, Valid: true}
+	}
+
+	if err := service.createAccessCode(c.Request.Context(), accessCode); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		case errors.Is(err, ErrTicketNotHidden):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrDuplicateCode):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed creating access code", "ticketId", ticketId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, AccessCodeEntityToAccessCode(accessCode))
+}
+
+func (service *AccessCodesService) GetTicketAccessCodesHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+
+	accessCodes, err := service.getTicketAccessCodes(c.Request.Context(), ticketId)
+	if err != nil {
+		service.logger.Error("failed to get access codes", "ticketId", ticketId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": AccessCodeEntitiesToAccessCodes(accessCodes)})
+}
+
+func (service *AccessCodesService) DisableAccessCodeHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+	accessCodeId, err := uuid.Parse(c.Param("codeId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "access code not found"})
+		return
+	}
+
+	accessCode, err := service.disableAccessCode(c.Request.Context(), ticketId, accessCodeId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "access code not found"})
+			return
+		}
+		service.logger.Error("failed disabling access code", "accessCodeId", accessCodeId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, AccessCodeEntityToAccessCode(accessCode))
+}
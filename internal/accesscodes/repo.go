@@ -0,0 +1,48 @@
+package accesscodes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+func (service *AccessCodesService) createAccessCode(ctx context.Context, accessCode *entities.AccessCode) error {
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ticket entities.Ticket
+		if err := tx.Select("id", "visibility").Where("id = ?", accessCode.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if ticket.Visibility != entities.TicketHidden {
+			return ErrTicketNotHidden
+		}
+		accessCode.ID = uuid.New()
+		return tx.Create(accessCode).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateCode
+	}
+	return err
+}
+
+func (service *AccessCodesService) getTicketAccessCodes(ctx context.Context, ticketId uuid.UUID) ([]entities.AccessCode, error) {
+	var accessCodes []entities.AccessCode
+	err := service.db.WithContext(ctx).Where("ticket_id = ?", ticketId).Order("created_at DESC").Find(&accessCodes).Error
+	return accessCodes, err
+}
+
+// disableAccessCode stops a code from unlocking its ticket. Orders already
+// placed with it stand.
+func (service *AccessCodesService) disableAccessCode(ctx context.Context, ticketId, accessCodeId uuid.UUID) (*entities.AccessCode, error) {
+	var accessCode entities.AccessCode
+	if err := service.db.WithContext(ctx).Where("id = ? AND ticket_id = ?", accessCodeId, ticketId).First(&accessCode).Error; err != nil {
+		return nil, err
+	}
+	accessCode.Active = false
+	if err := service.db.WithContext(ctx).Model(&accessCode).Update("active", false).Error; err != nil {
+		return nil, err
+	}
+	return &accessCode, nil
+}
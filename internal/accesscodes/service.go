@@ -0,0 +1,24 @@
+package accesscodes
+
+import (
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccessCodeRequired = errors.New("ticket requires an access code")
+	ErrInvalidAccessCode  = errors.New("invalid access code")
+	ErrDuplicateCode      = errors.New("ticket already has this access code")
+	ErrTicketNotHidden    = errors.New("access codes only apply to hidden tickets")
+)
+
+type AccessCodesService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAccessCodesService(db *gorm.DB, logger *slog.Logger) *AccessCodesService {
+	return &AccessCodesService{db: db, logger: logger}
+}
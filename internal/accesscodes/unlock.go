@@ -0,0 +1,42 @@
+package accesscodes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Unlock checks that code opens ticket to purchase and returns the id of the
+// matching code. Public tickets need no code and yield a null id.
+func Unlock(tx *gorm.DB, ticket *entities.Ticket, code *string) (uuid.NullUUID, error) {
+	if ticket.Visibility != entities.TicketHidden {
+		return uuid.NullUUID{}, nil
+	}
+	if code == nil {
+		return uuid.NullUUID{}, ErrAccessCodeRequired
+	}
+
+	var accessCode entities.AccessCode
+	err := tx.Where("ticket_id = ? AND code = ?", ticket.ID, utils.NormalizeCode(*code)).First(&accessCode).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.NullUUID{}, ErrInvalidAccessCode
+	}
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if !accessCode.Valid(time.Now()) {
+		return uuid.NullUUID{}, ErrInvalidAccessCode
+	}
+	return uuid.NullUUID{UUID: accessCode.ID, Valid: true}, nil
+}
+
+// Unlocked selects the ids of the tickets code currently unlocks, for use as a
+// subquery.
+func Unlocked(db *gorm.DB, code string) *gorm.DB {
+	return db.Model(&entities.AccessCode{}).Select("ticket_id").
+		Where("code = ? AND active AND (valid_until IS NULL OR valid_until > ?)", utils.NormalizeCode(code), time.Now())
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
 	"gorm.io/gorm"
 )
 
@@ -54,6 +55,19 @@ func AuthMiddleware(jwtService *JWTService) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware identifies the caller like AuthMiddleware when a token
+// is given, and lets anonymous requests through otherwise.
+func OptionalAuthMiddleware(jwtService *JWTService) gin.HandlerFunc {
+	authenticate := AuthMiddleware(jwtService)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
 func RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
@@ -116,7 +130,21 @@ func RequireRoles(requiredRoles []string) gin.HandlerFunc {
 	}
 }
 
-func RequireEntityOwnershipOrRole(db *gorm.DB, entity any, requiredRole string) gin.HandlerFunc {
+// Delegate reports whether userID was granted access to the resource
+// resourceID by its owner.
+type Delegate func(db *gorm.DB, resourceID, userID uuid.UUID) (bool, error)
+
+// EventStaff is a Delegate letting staff members of an event through.
+func EventStaff(db *gorm.DB, eventID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&entities.EventStaff{}).Where("event_id = ? AND user_id = ?", eventID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// RequireEntityOwnershipOrRole allows users with requiredRole, the owner of the
+// entity identified by the id route parameter, and users any of delegates
+// grant access to.
+func RequireEntityOwnershipOrRole(db *gorm.DB, entity any, requiredRole string, delegates ...Delegate) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
@@ -182,13 +210,27 @@ func RequireEntityOwnershipOrRole(db *gorm.DB, entity any, requiredRole string)
 		}
 
 		// Check if user owns the resource
-		if userUUID != entityOwner.UserId {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: not resource owner"})
-			c.Abort()
+		if userUUID == entityOwner.UserId {
+			c.Next()
 			return
 		}
 
-		c.Next()
+		// Otherwise, check whether the owner delegated access
+		for _, delegate := range delegates {
+			allowed, err := delegate(db, resourceUUID, userUUID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+				c.Abort()
+				return
+			}
+			if allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: not resource owner"})
+		c.Abort()
 	}
 }
 
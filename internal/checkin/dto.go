@@ -0,0 +1,127 @@
+package checkin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/passes"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+// ScanDTO carries what a scanner read: either the raw pass code or the
+// signed QR payload.
+type ScanDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (s *ScanDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(len(s.Code) > 0 && len(s.Code) <= 512, "code", "code must be between 1 and 512 characters")
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+// passCode extracts the pass code from a scan, verifying QR payloads.
+func passCode(secret []byte, scanned string) (string, error) {
+	if !strings.HasPrefix(scanned, "TICKR1.") {
+		return scanned, nil
+	}
+	_, code, err := passes.ParseQRPayload(secret, scanned)
+	return code, err
+}
+
+type ScanResult struct {
+	Result      string     `json:"result"`
+	PassId      *uuid.UUID `json:"pass_id,omitempty"`
+	TicketId    *uuid.UUID `json:"ticket_id,omitempty"`
+	UserId      *uuid.UUID `json:"user_id,omitempty"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+func NewScanResult(result string, pass *entities.Pass) ScanResult {
+	scan := ScanResult{Result: result}
+	if pass != nil {
+		scan.PassId = &pass.ID
+		scan.TicketId = &pass.TicketId
+		scan.UserId = &pass.UserId
+		if pass.CheckedInAt.Valid {
+			scan.CheckedInAt = &pass.CheckedInAt.Time
+		}
+	}
+	return scan
+}
+
+type TicketStats struct {
+	TicketId  uuid.UUID `json:"ticket_id"`
+	Name      string    `json:"name"`
+	Admitted  int       `json:"admitted"`
+	CheckedIn int       `json:"checked_in"`
+}
+
+// maxSyncScans caps how many offline scans one sync request may upload.
+const maxSyncScans = 1000
+
+// syncClockSkew is how far in the future an offline scan may be dated.
+const syncClockSkew = 5 * time.Minute
+
+type OfflineScanDTO struct {
+	// ScanId is unique per device, so a scan uploaded twice is applied once
+	ScanId    string    `json:"scan_id"`
+	Code      string    `json:"code"`
+	Action    string    `json:"action"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+type SyncDTO struct {
+	DeviceId string           `json:"device_id"`
+	Scans    []OfflineScanDTO `json:"scans" binding:"required"`
+}
+
+func (s *SyncDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(len(s.DeviceId) > 0 && len(s.DeviceId) <= 100, "device_id", "device_id must be between 1 and 100 characters")
+	v.Must(len(s.Scans) > 0 && len(s.Scans) <= maxSyncScans, "scans", fmt.Sprintf("scans must contain between 1 and %d entries", maxSyncScans))
+
+	latest := time.Now().Add(syncClockSkew)
+	scanIds := make(map[string]bool, len(s.Scans))
+	for i, scan := range s.Scans {
+		field := fmt.Sprintf("scans[%d]", i)
+		v.Must(len(scan.ScanId) > 0 && len(scan.ScanId) <= 100, field+".scan_id", "scan_id must be between 1 and 100 characters")
+		v.Must(!scanIds[scan.ScanId], field+".scan_id", "scan_id must be unique")
+		scanIds[scan.ScanId] = true
+		v.Must(len(scan.Code) > 0 && len(scan.Code) <= 512, field+".code", "code must be between 1 and 512 characters")
+		v.In(scan.Action, []string{entities.CheckinActionCheckin, entities.CheckinActionUndo}, field+".action", "action must be checkin or undo")
+		v.Must(!scan.ScannedAt.IsZero(), field+".scanned_at", "scanned_at is required")
+		v.Must(scan.ScannedAt.Before(latest), field+".scanned_at", "scanned_at must not be in the future")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+// SyncResult reports what the server made of one uploaded scan. Conflict is
+// set when the outcome differs from what the device assumed offline, i.e. the
+// scan was neither accepted nor undone. Scans an earlier sync already applied
+// are marked Replayed and carry the result they got then.
+type SyncResult struct {
+	Index  int    `json:"index"`
+	ScanId string `json:"scan_id"`
+	ScanResult
+	Conflict bool `json:"conflict"`
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+func NewSyncResult(index int, scanId, result string, pass *entities.Pass) SyncResult {
+	return SyncResult{
+		Index:      index,
+		ScanId:     scanId,
+		ScanResult: NewScanResult(result, pass),
+		Conflict:   result != entities.CheckinAccepted && result != entities.CheckinUndone,
+	}
+}
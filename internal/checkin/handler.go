@@ -0,0 +1,165 @@
+package checkin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+func (service *CheckinService) CheckinHandler(c *gin.Context) {
+	service.scan(c, service.checkIn)
+}
+
+func (service *CheckinService) UndoCheckinHandler(c *gin.Context) {
+	service.scan(c, service.undoCheckIn)
+}
+
+type scanFunc func(ctx context.Context, scan scanRequest) (*entities.Pass, string, error)
+
+func (service *CheckinService) scan(c *gin.Context, apply scanFunc) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	var input ScanDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	scannerId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	code, err := passCode(service.secret, input.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewScanResult(entities.CheckinUnknown, nil))
+		return
+	}
+
+	pass, result, err := apply(c.Request.Context(), scanRequest{
+		EventId:   eventId,
+		ScannerId: scannerId,
+		Code:      code,
+		ScannedAt: time.Now(),
+		Source:    entities.CheckinSourceOnline,
+	})
+	if err != nil {
+		service.logger.Error("check-in failed", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	status := http.StatusOK
+	switch result {
+	case entities.CheckinUnknown:
+		status = http.StatusNotFound
+	case entities.CheckinDuplicate, entities.CheckinVoid, entities.CheckinNotUsed:
+		status = http.StatusConflict
+	}
+	c.JSON(status, NewScanResult(result, pass))
+}
+
+func (service *CheckinService) GetCheckinStatsHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	stats, err := service.getCheckinStats(c.Request.Context(), eventId)
+	if err != nil {
+		service.logger.Error("failed to get check-in stats", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	var admitted, checkedIn int
+	for _, s := range stats {
+		admitted += s.Admitted
+		checkedIn += s.CheckedIn
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       stats,
+		"admitted":   admitted,
+		"checked_in": checkedIn,
+	})
+}
+
+func (service *CheckinService) GetManifestHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	bundle, err := service.buildManifest(c.Request.Context(), eventId)
+	if err != nil {
+		service.logger.Error("failed building check-in manifest", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, bundle)
+}
+
+func (service *CheckinService) GetManifestKeyHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, manifestKey(service.signingKey))
+}
+
+func (service *CheckinService) SyncHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	var input SyncDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	scannerId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	results, err := service.syncScans(c.Request.Context(), eventId, scannerId, input)
+	if err != nil {
+		service.logger.Error("check-in sync failed", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	conflicts := 0
+	for _, r := range results {
+		if r.Conflict {
+			conflicts++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      results,
+		"conflicts": conflicts,
+	})
+}
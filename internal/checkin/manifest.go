@@ -0,0 +1,118 @@
+package checkin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+// manifestVersion versions the manifest format scanner devices parse.
+const manifestVersion = 1
+
+var ErrInvalidSigningKey = errors.New("CHECKIN_SIGNING_KEY must be a base64 encoded 32 byte Ed25519 seed")
+
+// ManifestSigningKey returns the key offline manifests are signed with, from
+// CHECKIN_SIGNING_KEY, a base64 encoded 32 byte Ed25519 seed. Devices pin its
+// public half, so there is no fallback key.
+func ManifestSigningKey() (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(os.Getenv("CHECKIN_SIGNING_KEY"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidSigningKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Manifest lists every admission to an event so a scanner can validate codes
+// without a connection. Codes are only present hashed.
+type Manifest struct {
+	Version     int             `json:"v"`
+	EventId     uuid.UUID       `json:"event_id"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Passes      []ManifestEntry `json:"passes"`
+}
+
+type ManifestEntry struct {
+	Hash     string    `json:"h"` // see hashCode
+	TicketId uuid.UUID `json:"t"`
+	Used     bool      `json:"u,omitempty"` // already checked in when generated
+}
+
+// ManifestBundle carries the manifest JSON exactly as signed, base64url
+// encoded, with its detached signature. KeyId names the key it was signed
+// with; devices verify with the public key they pinned from ManifestKey and
+// never with one that comes alongside a manifest.
+type ManifestBundle struct {
+	Manifest  string `json:"manifest"`
+	Signature string `json:"signature"`
+	KeyId     string `json:"key_id"`
+}
+
+// ManifestKey is the public half of the manifest signing key, for devices
+// to pin when they are set up.
+type ManifestKey struct {
+	KeyId     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+// manifestKey describes the public half of key. Its id is the start of the
+// key's SHA-256, enough to tell a rotated key apart.
+func manifestKey(key ed25519.PrivateKey) ManifestKey {
+	publicKey := key.Public().(ed25519.PublicKey)
+	sum := sha256.Sum256(publicKey)
+	return ManifestKey{
+		KeyId:     base64.RawURLEncoding.EncodeToString(sum[:8]),
+		PublicKey: base64.RawURLEncoding.EncodeToString(publicKey),
+	}
+}
+
+// hashCode is how pass codes appear in manifests: the unpadded base64url
+// SHA-256 of the code.
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// buildManifest snapshots the event's valid and used passes and signs them.
+func (service *CheckinService) buildManifest(ctx context.Context, eventId uuid.UUID) (*ManifestBundle, error) {
+	var admissions []entities.Pass
+	err := service.db.WithContext(ctx).
+		Select("id", "ticket_id", "code", "status").
+		Where("event_id = ? AND status IN ?", eventId, []string{entities.PassValid, entities.PassUsed}).
+		Order("id").
+		Find(&admissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := Manifest{
+		Version:     manifestVersion,
+		EventId:     eventId,
+		GeneratedAt: time.Now().UTC(),
+		Passes:      make([]ManifestEntry, 0, len(admissions)),
+	}
+	for _, pass := range admissions {
+		manifest.Passes = append(manifest.Passes, ManifestEntry{
+			Hash:     hashCode(pass.Code),
+			TicketId: pass.TicketId,
+			Used:     pass.Status == entities.PassUsed,
+		})
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &ManifestBundle{
+		Manifest:  base64.RawURLEncoding.EncodeToString(body),
+		Signature: base64.RawURLEncoding.EncodeToString(ed25519.Sign(service.signingKey, body)),
+		KeyId:     manifestKey(service.signingKey).KeyId,
+	}, nil
+}
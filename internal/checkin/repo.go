@@ -0,0 +1,137 @@
+package checkin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// scanRequest is a single scan, made live or replayed from an offline log.
+type scanRequest struct {
+	EventId   uuid.UUID
+	ScannerId uuid.UUID
+	Code      string
+	ScannedAt time.Time
+	Source    string
+	DeviceId  string
+	ScanId    string
+}
+
+// checkIn marks the event's pass with code as used. The pass row is locked so
+// two gates scanning the same pass cannot both admit it. Every scan is logged.
+func (service *CheckinService) checkIn(ctx context.Context, scan scanRequest) (*entities.Pass, string, error) {
+	var pass *entities.Pass
+	var result string
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		pass, err = lockPass(tx, scan.EventId, scan.Code)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case pass == nil:
+			result = entities.CheckinUnknown
+		case pass.Status == entities.PassUsed:
+			result = entities.CheckinDuplicate
+		case pass.Status == entities.PassVoid:
+			result = entities.CheckinVoid
+		default:
+			result = entities.CheckinAccepted
+			pass.Status = entities.PassUsed
+			pass.CheckedInAt = sql.NullTime{Time: scan.ScannedAt, Valid: true}
+			if err := tx.Model(pass).Select("status", "checked_in_at").Updates(pass).Error; err != nil {
+				return err
+			}
+		}
+		return logScan(tx, scan, pass, entities.CheckinActionCheckin, result)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return pass, result, nil
+}
+
+// undoCheckIn puts a used pass back to valid, e.g. after a mistaken scan.
+func (service *CheckinService) undoCheckIn(ctx context.Context, scan scanRequest) (*entities.Pass, string, error) {
+	var pass *entities.Pass
+	var result string
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		pass, err = lockPass(tx, scan.EventId, scan.Code)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case pass == nil:
+			result = entities.CheckinUnknown
+		case pass.Status != entities.PassUsed:
+			result = entities.CheckinNotUsed
+		default:
+			result = entities.CheckinUndone
+			pass.Status = entities.PassValid
+			pass.CheckedInAt = sql.NullTime{}
+			if err := tx.Model(pass).Select("status", "checked_in_at").Updates(pass).Error; err != nil {
+				return err
+			}
+		}
+		return logScan(tx, scan, pass, entities.CheckinActionUndo, result)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return pass, result, nil
+}
+
+// lockPass locks the event's pass with code, returning nil if there is none.
+func lockPass(tx *gorm.DB, eventId uuid.UUID, code string) (*entities.Pass, error) {
+	var pass entities.Pass
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("event_id = ? AND code = ?", eventId, code).First(&pass).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pass, nil
+}
+
+func logScan(tx *gorm.DB, scan scanRequest, pass *entities.Pass, action, result string) error {
+	entry := entities.CheckinScan{
+		ID:        uuid.New(),
+		EventId:   scan.EventId,
+		UserId:    scan.ScannerId,
+		Action:    action,
+		Result:    result,
+		Source:    scan.Source,
+		DeviceId:  scan.DeviceId,
+		ScanId:    scan.ScanId,
+		ScannedAt: scan.ScannedAt,
+	}
+	if pass != nil {
+		entry.PassId = uuid.NullUUID{UUID: pass.ID, Valid: true}
+	}
+	return tx.Create(&entry).Error
+}
+
+// getCheckinStats counts admitted and checked in passes per ticket type.
+func (service *CheckinService) getCheckinStats(ctx context.Context, eventId uuid.UUID) ([]TicketStats, error) {
+	var stats []TicketStats
+	err := service.db.WithContext(ctx).Model(&entities.Ticket{}).
+		Select(`tickets.id AS ticket_id, tickets.name,
+			COUNT(passes.id) FILTER (WHERE passes.status <> ?) AS admitted,
+			COUNT(passes.id) FILTER (WHERE passes.status = ?) AS checked_in`, entities.PassVoid, entities.PassUsed).
+		Joins("LEFT JOIN passes ON passes.ticket_id = tickets.id").
+		Where("tickets.event_id = ?", eventId).
+		Group("tickets.id, tickets.name").
+		Order("tickets.name ASC").
+		Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,33 @@
+package checkin
+
+import (
+	"crypto/ed25519"
+	"log/slog"
+
+	"github.com/rezbow/tickr/internal/passes"
+	"gorm.io/gorm"
+)
+
+type CheckinService struct {
+	db         *gorm.DB
+	logger     *slog.Logger
+	secret     []byte             // verifies scanned QR payloads
+	signingKey ed25519.PrivateKey // signs offline manifests
+}
+
+func NewCheckinService(db *gorm.DB, logger *slog.Logger) *CheckinService {
+	secret, err := passes.SigningSecret()
+	if err != nil {
+		panic(err.Error())
+	}
+	signingKey, err := ManifestSigningKey()
+	if err != nil {
+		panic(err.Error())
+	}
+	return &CheckinService{
+		db:         db,
+		logger:     logger,
+		secret:     secret,
+		signingKey: signingKey,
+	}
+}
@@ -0,0 +1,100 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+// syncScans replays an offline scan log against the server in the order the
+// scans happened. Each scan goes through the same locked transition as a live
+// one, so a pass admitted at two offline gates, or online and offline, is
+// accepted once and reported as a conflict everywhere else. Scans are applied
+// once per device and scan id; uploading them again, as a retry after a
+// failed sync does, returns the results they got the first time.
+func (service *CheckinService) syncScans(ctx context.Context, eventId, scannerId uuid.UUID, input SyncDTO) ([]SyncResult, error) {
+	order := make([]int, len(input.Scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return input.Scans[order[a]].ScannedAt.Before(input.Scans[order[b]].ScannedAt)
+	})
+
+	results := make([]SyncResult, len(input.Scans))
+	for _, i := range order {
+		offline := input.Scans[i]
+		applied, err := service.appliedScan(ctx, eventId, input.DeviceId, offline.ScanId, i)
+		if err != nil {
+			return nil, err
+		}
+		if applied != nil {
+			results[i] = *applied
+			continue
+		}
+
+		code, err := passCode(service.secret, offline.Code)
+		if err != nil {
+			results[i] = NewSyncResult(i, offline.ScanId, entities.CheckinUnknown, nil)
+			continue
+		}
+
+		scan := scanRequest{
+			EventId:   eventId,
+			ScannerId: scannerId,
+			Code:      code,
+			ScannedAt: offline.ScannedAt,
+			Source:    entities.CheckinSourceOffline,
+			DeviceId:  input.DeviceId,
+			ScanId:    offline.ScanId,
+		}
+		apply := service.checkIn
+		if offline.Action == entities.CheckinActionUndo {
+			apply = service.undoCheckIn
+		}
+		pass, result, err := apply(ctx, scan)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			// a concurrent upload of the same log applied it first
+			applied, err = service.appliedScan(ctx, eventId, input.DeviceId, offline.ScanId, i)
+			if err == nil && applied != nil {
+				results[i] = *applied
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[i] = NewSyncResult(i, offline.ScanId, result, pass)
+	}
+	return results, nil
+}
+
+// appliedScan returns the result a device's scan got when it was first
+// applied, or nil if it never was.
+func (service *CheckinService) appliedScan(ctx context.Context, eventId uuid.UUID, deviceId, scanId string, index int) (*SyncResult, error) {
+	var scan entities.CheckinScan
+	err := service.db.WithContext(ctx).
+		Where("event_id = ? AND device_id = ? AND scan_id = ?", eventId, deviceId, scanId).
+		First(&scan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pass *entities.Pass
+	if scan.PassId.Valid {
+		pass = &entities.Pass{}
+		if err := service.db.WithContext(ctx).Where("id = ?", scan.PassId.UUID).First(pass).Error; err != nil {
+			return nil, err
+		}
+	}
+	result := NewSyncResult(index, scanId, scan.Result, pass)
+	result.Replayed = true
+	return &result, nil
+}
@@ -0,0 +1,68 @@
+package currency
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+// minorUnits maps the ISO 4217 currencies tickets can be sold in to the
+// number of digits after their decimal separator. Amounts everywhere are
+// integers in the currency's minor unit: cents for USD, yen for JPY, fils
+// for KWD.
+var minorUnits = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2,
+	"CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2,
+	"DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2,
+	"IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KES": 2, "KRW": 0, "KWD": 3, "MAD": 2, "MXN": 2, "MYR": 2,
+	"NGN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PEN": 2, "PHP": 2,
+	"PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "SAR": 2, "SEK": 2,
+	"SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2, "UAH": 2,
+	"USD": 2, "VND": 0, "ZAR": 2,
+}
+
+const fallback = "USD"
+
+// Valid reports whether code is a supported ISO 4217 currency.
+func Valid(code string) bool {
+	_, ok := minorUnits[utils.NormalizeCode(code)]
+	return ok
+}
+
+// MinorUnits is the number of decimal digits of code, two when unknown.
+func MinorUnits(code string) int {
+	if units, ok := minorUnits[utils.NormalizeCode(code)]; ok {
+		return units
+	}
+	return 2
+}
+
+// Default is the currency of events created without one. DEFAULT_CURRENCY
+// overrides USD.
+func Default() string {
+	if code := utils.NormalizeCode(os.Getenv("DEFAULT_CURRENCY")); Valid(code) {
+		return code
+	}
+	return fallback
+}
+
+// Format renders amount minor units of code as a decimal, e.g. 1250 USD as
+// "12.50 USD" and 1250 JPY as "1250 JPY".
+func Format(amount int64, code string) string {
+	units := MinorUnits(code)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if units == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, code)
+	}
+	scale := int64(1)
+	for range units {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, units, amount%scale, code)
+}
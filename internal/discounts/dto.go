@@ -0,0 +1,103 @@
+package discounts
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+type DiscountCreateDTO struct {
+	Code           string      `json:"code" binding:"required"`
+	Kind           string      `json:"kind" binding:"required"`
+	Value          int64       `json:"value" binding:"required"`
+	MaxUses        *int        `json:"max_uses"`
+	MaxUsesPerUser *int        `json:"max_uses_per_user"`
+	ValidFrom      *time.Time  `json:"valid_from"`
+	ValidUntil     *time.Time  `json:"valid_until"`
+	TicketIds      []uuid.UUID `json:"ticket_ids"` // empty applies to every ticket of the event
+}
+
+func (d *DiscountCreateDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Regex(utils.NormalizeCode(d.Code), codePattern, "code", "code must be 3 to 32 letters, digits, dashes or underscores")
+	v.In(d.Kind, entities.DiscountKinds, "kind", "kind must be one of percent, fixed")
+	v.Must(d.Value > 0, "value", "must be positive integer")
+	if d.Kind == entities.DiscountPercent {
+		v.Must(d.Value <= 100, "value", "percent discounts must not exceed 100")
+	}
+	if d.MaxUses != nil {
+		v.Must(*d.MaxUses >= 0, "max_uses", "must not be negative")
+	}
+	if d.MaxUsesPerUser != nil {
+		v.Must(*d.MaxUsesPerUser >= 0, "max_uses_per_user", "must not be negative")
+	}
+	if d.ValidFrom != nil && d.ValidUntil != nil {
+		v.Must(d.ValidUntil.After(*d.ValidFrom), "valid_until", "valid_until should be after valid_from")
+	}
+	if d.ValidUntil != nil {
+		v.Must(d.ValidUntil.After(time.Now()), "valid_until", "valid_until should be in future")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type Discount struct {
+	ID             uuid.UUID   `json:"id"`
+	EventId        uuid.UUID   `json:"event_id"`
+	Code           string      `json:"code"`
+	Kind           string      `json:"kind"`
+	Value          int64       `json:"value"`
+	Currency       string      `json:"currency,omitempty"` // of value, for fixed discounts
+	MaxUses        int         `json:"max_uses,omitempty"`
+	MaxUsesPerUser int         `json:"max_uses_per_user,omitempty"`
+	UsedCount      int         `json:"used_count"`
+	ValidFrom      *time.Time  `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time  `json:"valid_until,omitempty"`
+	TicketIds      []uuid.UUID `json:"ticket_ids"`
+	Active         bool        `json:"active"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func DiscountEntityToDiscount(d *entities.DiscountCode) Discount {
+	discount := Discount{
+		ID:             d.ID,
+		EventId:        d.EventId,
+		Code:           d.Code,
+		Kind:           d.Kind,
+		Value:          d.Value,
+		MaxUses:        d.MaxUses,
+		MaxUsesPerUser: d.MaxUsesPerUser,
+		UsedCount:      d.UsedCount,
+		TicketIds:      make([]uuid.UUID, len(d.Tickets)),
+		Active:         d.Active,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Kind == entities.DiscountFixed {
+		discount.Currency = d.Currency
+	}
+	if d.ValidFrom.Valid {
+		discount.ValidFrom = &d.ValidFrom.Time
+	}
+	if d.ValidUntil.Valid {
+		discount.ValidUntil = &d.ValidUntil.Time
+	}
+	for i, t := range d.Tickets {
+		discount.TicketIds[i] = t.TicketId
+	}
+	return discount
+}
+
+func DiscountEntitiesToDiscounts(discounts []entities.DiscountCode) []Discount {
+	result := make([]Discount, len(discounts))
+	for i := range discounts {
+		result[i] = DiscountEntityToDiscount(&discounts[i])
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package discounts
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (service *DiscountsService) CreateDiscountHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	var input DiscountCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	discount := &entities.DiscountCode{
+		EventId: eventId,
+		UserId:  userId,
+		Code:    utils.NormalizeCode(input.Code),
+		Kind:    input.Kind,
+		Value:   input.Value,
+		Active:  true,
+	}
+	if input.MaxUses != nil {
+		discount.MaxUses = *input.MaxUses
+	}
+	if input.MaxUsesPerUser != nil {
+		discount.MaxUsesPerUser = *input.MaxUsesPerUser
+	}
+	if input.ValidFrom != nil {
+		discount.ValidFrom = sql.NullTime{Time: *input.ValidFrom, Valid: true}
+	}
+	if input.ValidUntil != nil {
+		discount.ValidUntil = sql.NullTime{Time: *input.ValidUntil, Valid: true}
+	}
+
+	if err := service.createDiscount(c.Request.Context(), discount, input.TicketIds); err != nil {
+		switch {
+		case errors.Is(err, ErrTicketNotInEvent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrDuplicateCode):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, gorm.ErrForeignKeyViolated):
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		default:
+			service.logger.Error("failed creating discount", "eventId", eventId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, DiscountEntityToDiscount(discount))
+}
+
+func (service *DiscountsService) GetEventDiscountsHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	discounts, err := service.getEventDiscounts(c.Request.Context(), eventId)
+	if err != nil {
+		service.logger.Error("failed to get discounts", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": DiscountEntitiesToDiscounts(discounts)})
+}
+
+func (service *DiscountsService) DisableDiscountHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+	discountId, err := uuid.Parse(c.Param("discountId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "discount not found"})
+		return
+	}
+
+	discount, err := service.disableDiscount(c.Request.Context(), eventId, discountId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "discount not found"})
+			return
+		}
+		service.logger.Error("failed disabling discount", "discountId", discountId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, DiscountEntityToDiscount(discount))
+}
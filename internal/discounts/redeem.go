@@ -0,0 +1,109 @@
+package discounts
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Redeem uses code for payment, which pays for order on ticket, and returns
+// the recorded redemption. The code row is locked until tx ends, so
+// concurrent payments are counted one after the other against the code's
+// limits.
+func Redeem(tx *gorm.DB, code string, order *entities.Order, ticket *entities.Ticket, payment *entities.Payment) (*entities.DiscountRedemption, error) {
+	discount, err := applicable(tx.Clauses(clause.Locking{Strength: "UPDATE"}), code, order, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(discount).Update("used_count", discount.UsedCount+1).Error; err != nil {
+		return nil, err
+	}
+	redemption := entities.DiscountRedemption{
+		ID:             uuid.New(),
+		DiscountCodeId: discount.ID,
+		PaymentId:      payment.ID,
+		UserId:         order.UserId,
+		Amount:         discount.Discount(order.Amount),
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+// Quote reports what code would take off order on ticket, without using it.
+func Quote(tx *gorm.DB, code string, order *entities.Order, ticket *entities.Ticket) (int64, error) {
+	discount, err := applicable(tx, code, order, ticket)
+	if err != nil {
+		return 0, err
+	}
+	return discount.Discount(order.Amount), nil
+}
+
+// applicable loads code for ticket's event and checks that order may use it.
+func applicable(tx *gorm.DB, code string, order *entities.Order, ticket *entities.Ticket) (*entities.DiscountCode, error) {
+	var discount entities.DiscountCode
+	err := tx.Preload("Tickets").
+		Where("event_id = ? AND code = ? AND active", ticket.EventId, utils.NormalizeCode(code)).
+		First(&discount).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !discount.Valid(time.Now()) {
+		return nil, ErrCodeNotValid
+	}
+	if discount.Kind == entities.DiscountFixed && discount.Currency != ticket.Currency {
+		return nil, ErrCodeNotApplicable
+	}
+	if len(discount.Tickets) > 0 && !slices.ContainsFunc(discount.Tickets, func(t entities.DiscountTicket) bool {
+		return t.TicketId == ticket.ID
+	}) {
+		return nil, ErrCodeNotApplicable
+	}
+	if discount.MaxUses > 0 && discount.UsedCount >= discount.MaxUses {
+		return nil, ErrCodeExhausted
+	}
+	if discount.MaxUsesPerUser > 0 {
+		var used int64
+		err := tx.Session(&gorm.Session{NewDB: true}).Model(&entities.DiscountRedemption{}).
+			Where("discount_code_id = ? AND user_id = ?", discount.ID, order.UserId).
+			Count(&used).Error
+		if err != nil {
+			return nil, err
+		}
+		if int(used) >= discount.MaxUsesPerUser {
+			return nil, ErrCodeUserLimit
+		}
+	}
+	return &discount, nil
+}
+
+// Release gives back the code use of a payment that never completed.
+func Release(tx *gorm.DB, paymentId uuid.UUID) error {
+	var redemptions []entities.DiscountRedemption
+	if err := tx.Where("payment_id = ?", paymentId).Find(&redemptions).Error; err != nil {
+		return err
+	}
+	for _, r := range redemptions {
+		err := tx.Model(&entities.DiscountCode{}).Where("id = ?", r.DiscountCodeId).
+			Update("used_count", gorm.Expr("used_count - 1")).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&r).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package discounts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+func (service *DiscountsService) createDiscount(ctx context.Context, discount *entities.DiscountCode, ticketIds []uuid.UUID) error {
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event entities.Event
+		if err := tx.Select("id", "currency").Where("id = ?", discount.EventId).First(&event).Error; err != nil {
+			return err
+		}
+		// fixed amounts are in the event's currency, and apply to no ticket
+		// priced in another
+		discount.Currency = event.Currency
+
+		if len(ticketIds) > 0 {
+			var count int64
+			err := tx.Model(&entities.Ticket{}).Where("id IN ? AND event_id = ?", ticketIds, discount.EventId).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if int(count) != len(ticketIds) {
+				return ErrTicketNotInEvent
+			}
+		}
+
+		discount.ID = uuid.New()
+		discount.Tickets = make([]entities.DiscountTicket, len(ticketIds))
+		for i, id := range ticketIds {
+			discount.Tickets[i] = entities.DiscountTicket{ID: uuid.New(), DiscountCodeId: discount.ID, TicketId: id}
+		}
+		return tx.Create(discount).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateCode
+	}
+	return err
+}
+
+func (service *DiscountsService) getEventDiscounts(ctx context.Context, eventId uuid.UUID) ([]entities.DiscountCode, error) {
+	var discounts []entities.DiscountCode
+	err := service.db.WithContext(ctx).Preload("Tickets").Where("event_id = ?", eventId).Order("created_at DESC").Find(&discounts).Error
+	return discounts, err
+}
+
+// disableDiscount stops a code from being redeemed. Past redemptions stand.
+func (service *DiscountsService) disableDiscount(ctx context.Context, eventId, discountId uuid.UUID) (*entities.DiscountCode, error) {
+	var discount entities.DiscountCode
+	if err := service.db.WithContext(ctx).Preload("Tickets").Where("id = ? AND event_id = ?", discountId, eventId).First(&discount).Error; err != nil {
+		return nil, err
+	}
+	discount.Active = false
+	if err := service.db.WithContext(ctx).Model(&discount).Update("active", false).Error; err != nil {
+		return nil, err
+	}
+	return &discount, nil
+}
@@ -0,0 +1,27 @@
+package discounts
+
+import (
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCode       = errors.New("invalid discount code")
+	ErrCodeNotValid      = errors.New("discount code is not valid at this time")
+	ErrCodeNotApplicable = errors.New("discount code does not apply to this purchase")
+	ErrCodeExhausted     = errors.New("discount code has no uses left")
+	ErrCodeUserLimit     = errors.New("discount code already used the maximum number of times")
+	ErrTicketNotInEvent  = errors.New("ticket does not belong to this event")
+	ErrDuplicateCode     = errors.New("event already has this discount code")
+)
+
+type DiscountsService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewDiscountsService(db *gorm.DB, logger *slog.Logger) *DiscountsService {
+	return &DiscountsService{db: db, logger: logger}
+}
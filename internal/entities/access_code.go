@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+//
+// An AccessCode unlocks a hidden ticket: presenting it lists the ticket with
+// its event and lets the holder order it.
+type AccessCode struct {
+	ID         uuid.UUID
+	TicketId   uuid.UUID
+	UserId     uuid.UUID // creator
+	Code       string
+	ValidUntil sql.NullTime // null never expires
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Valid reports whether the code can unlock its ticket at now.
+func (a *AccessCode) Valid(now time.Time) bool {
+	return a.Active && (!a.ValidUntil.Valid || now.Before(a.ValidUntil.Time))
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	CheckinActionCheckin = "checkin"
+	CheckinActionUndo    = "undo"
+
+	CheckinAccepted  = "accepted"
+	CheckinDuplicate = "duplicate"
+	CheckinVoid      = "void"
+	CheckinUnknown   = "unknown"
+	CheckinUndone    = "undone"
+	CheckinNotUsed   = "not_checked_in"
+
+	CheckinSourceOnline  = "online"
+	CheckinSourceOffline = "offline"
+)
+
+// gorm model
+//
+// CheckinScan logs every scan made at the door, whatever its outcome.
+type CheckinScan struct {
+	ID        uuid.UUID
+	EventId   uuid.UUID
+	PassId    uuid.NullUUID // null when the code matched no pass
+	UserId    uuid.UUID     // who scanned
+	Action    string
+	Result    string
+	Source    string // online, or offline when uploaded through a sync
+	DeviceId  string
+	ScanId    string // the device's id of an offline scan
+	ScannedAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
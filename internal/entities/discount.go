@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+var DiscountKinds = []string{DiscountPercent, DiscountFixed}
+
+// gorm model
+//
+// A DiscountCode takes Value percent, or Value off the whole order, from
+// purchases of an event's tickets, or only of Tickets when any are listed.
+type DiscountCode struct {
+	ID             uuid.UUID
+	EventId        uuid.UUID
+	UserId         uuid.UUID // creator
+	Code           string
+	Kind           string
+	Value          int64
+	Currency       string // of Value, for fixed discounts
+	MaxUses        int    // zero means no limit
+	MaxUsesPerUser int    // zero means no limit
+	UsedCount      int
+	ValidFrom      sql.NullTime
+	ValidUntil     sql.NullTime
+	Active         bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	// associations
+	Tickets []DiscountTicket // has many
+}
+
+// Valid reports whether the code's validity window contains now.
+func (d *DiscountCode) Valid(now time.Time) bool {
+	if d.ValidFrom.Valid && now.Before(d.ValidFrom.Time) {
+		return false
+	}
+	if d.ValidUntil.Valid && !now.Before(d.ValidUntil.Time) {
+		return false
+	}
+	return true
+}
+
+// Discount is what the code takes off amount.
+func (d *DiscountCode) Discount(amount int64) int64 {
+	discount := d.Value
+	if d.Kind == DiscountPercent {
+		discount = amount * d.Value / 100
+	}
+	return min(discount, amount)
+}
+
+// gorm model
+type DiscountTicket struct {
+	ID             uuid.UUID
+	DiscountCodeId uuid.UUID
+	TicketId       uuid.UUID
+}
+
+// gorm model
+//
+// A DiscountRedemption is one use of a code by a payment. Payments that
+// never complete give their use back.
+type DiscountRedemption struct {
+	ID             uuid.UUID
+	DiscountCodeId uuid.UUID
+	PaymentId      uuid.UUID
+	UserId         uuid.UUID
+	Amount         int64
+	CreatedAt      time.Time
+}
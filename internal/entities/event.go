@@ -2,23 +2,97 @@ package entities
 
 import (
 	"database/sql"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	RefundPolicyNone        = "none"
+	RefundPolicyBeforeStart = "before_start"
+	RefundPolicyAnytime     = "anytime"
+)
+
+var RefundPolicies = []string{RefundPolicyNone, RefundPolicyBeforeStart, RefundPolicyAnytime}
+
+var (
+	FeeModePassThrough = "pass_through"
+	FeeModeAbsorbed    = "absorbed"
+)
+
+var FeeModes = []string{FeeModePassThrough, FeeModeAbsorbed}
+
+var (
+	EventDraft     = "draft"
+	EventPublished = "published"
+	EventPostponed = "postponed"
+	EventCanceled  = "canceled"
+	EventCompleted = "completed"
+)
+
+var EventStatuses = []string{EventDraft, EventPublished, EventPostponed, EventCanceled, EventCompleted}
+
+// eventTransitions lists the statuses an event may move to from each status.
+var eventTransitions = map[string][]string{
+	EventDraft:     {EventPublished, EventCanceled},
+	EventPublished: {EventPostponed, EventCanceled, EventCompleted},
+	EventPostponed: {EventPublished, EventCanceled},
+}
+
 // gorm model
 type Event struct {
 	ID          uuid.UUID
 	Title       string
 	Description sql.NullString
 	Venue       string
+	VenueId     uuid.NullUUID // set for events at a known venue, Venue then holds its name
 	UserId      uuid.UUID
 	StartTime   time.Time
 	EndTime     time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// Status moves along eventTransitions, only published events are listed
+	// and sell tickets
+	Status string
+	// RefundPolicy decides when organizers may refund payments for the event
+	RefundPolicy string
+	// Capacity caps the units sold or held across all the event's tickets,
+	// zero means no limit other than the venue's. It may not exceed the
+	// venue's MaxCapacity, which bounds the units issued.
+	Capacity int
+	// MaxTicketsPerUser caps the units one user may hold across all the
+	// event's tickets, zero means no limit
+	MaxTicketsPerUser int
+	// ResaleEnabled lets holders list passes on the resale marketplace at up
+	// to ResaleMaxMarkup percent over face value
+	ResaleEnabled   bool
+	ResaleMaxMarkup int
+	// FeeMode decides whether buyers pay the platform fee on top of the
+	// ticket price or the organizer absorbs it
+	FeeMode string
+	// TaxRate is charged on ticket sales, in basis points
+	TaxRate int
+	// Currency is the ISO 4217 code tickets of the event are priced in
+	// unless they name their own
+	Currency  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 	// associations
 	User    User     // Belongs to
 	Tickets []Ticket // has many
 }
+
+// CanTransition reports whether the event may move to status to.
+func (e *Event) CanTransition(to string) bool {
+	return slices.Contains(eventTransitions[e.Status], to)
+}
+
+// OnSale reports whether tickets to the event may be bought.
+func (e *Event) OnSale() bool {
+	return e.Status == EventPublished
+}
+
+// VisibleTo reports whether the event may be shown to userId holding role.
+// Drafts are only shown to their organizer and admins.
+func (e *Event) VisibleTo(userId uuid.UUID, role string) bool {
+	return e.Status != EventDraft || e.UserId == userId || role == "admin"
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+type EventChange struct {
+	ID      uuid.UUID
+	EventId uuid.UUID
+	UserId  uuid.NullUUID // who made the change, unset once they are deleted
+	// Field is the changed column, values are formatted as text
+	Field    string
+	OldValue string
+	NewValue string
+	// Notify flags changes attendees should hear about, such as a new date
+	Notify    bool
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+//
+// EventStaff grants a user door duties, such as check-in, for an event.
+type EventStaff struct {
+	ID        uuid.UUID
+	EventId   uuid.UUID
+	UserId    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// associations
+	User *User // belongs to
+}
@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+type IdempotencyKey struct {
+	ID    uuid.UUID
+	Key   string
+	Scope string // the authenticated user, or the client address for public routes
+	// sha256 of the method, route and body of the first request using the key
+	RequestHash         string
+	ResponseStatus      int
+	ResponseContentType string
+	ResponseBody        []byte
+	CompletedAt         sql.NullTime // null while the first request is in flight
+	ExpiresAt           time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+//
+// An Invoice numbers the receipt of a payment in the sequence of the
+// organizer whose event was paid for.
+type Invoice struct {
+	ID          uuid.UUID
+	PaymentId   uuid.UUID
+	OrganizerId uuid.UUID
+	Number      int
+	CreatedAt   time.Time
+}
+
+// gorm model
+//
+// An InvoiceCounter holds the last invoice number given out by an organizer.
+type InvoiceCounter struct {
+	UserId     uuid.UUID `gorm:"primaryKey"`
+	LastNumber int
+	UpdatedAt  time.Time
+}
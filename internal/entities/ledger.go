@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// AccountCash is money the platform holds, collected from buyers and
+	// not paid out yet
+	AccountCash = "cash"
+	// AccountPayable is what the platform owes a user: organizers for their
+	// events' sales, sellers for resold passes
+	AccountPayable = "payable"
+	// AccountRevenue is the platform's fees
+	AccountRevenue = "revenue"
+)
+
+var (
+	LedgerSale   = "sale"
+	LedgerRefund = "refund"
+	LedgerPayout = "payout"
+)
+
+// gorm model
+//
+// A LedgerEntry is one leg of a double-entry transaction. Debits are
+// positive, credits negative, and the legs sharing a TransactionId sum to
+// zero.
+type LedgerEntry struct {
+	ID            uuid.UUID
+	TransactionId uuid.UUID
+	Kind          string
+	Account       string
+	UserId        uuid.NullUUID // holder of a payable account
+	EventId       uuid.NullUUID
+	PaymentId     uuid.NullUUID
+	RefundId      uuid.NullUUID
+	// PayoutId is the payout that settled a payable entry, or that posted
+	// the entry
+	PayoutId  uuid.NullUUID
+	Amount    int64
+	Currency  string
+	CreatedAt time.Time
+}
+
+// gorm model
+//
+// A Payout settles what a user was owed in one currency for the payable
+// entries up to PeriodEnd.
+type Payout struct {
+	ID        uuid.UUID
+	UserId    uuid.UUID
+	Currency  string
+	Amount    int64
+	Entries   int // payable entries settled
+	PeriodEnd time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ListingActive   = "active"
+	ListingSold     = "sold"
+	ListingCanceled = "canceled"
+)
+
+// gorm model
+//
+// A Listing offers passes of a confirmed payment for resale at Price a unit.
+type Listing struct {
+	ID               uuid.UUID
+	UserId           uuid.UUID // seller
+	PaymentId        uuid.UUID
+	TicketId         uuid.UUID
+	EventId          uuid.UUID
+	Quantity         int
+	ReservedQuantity int // held by pending orders
+	SoldQuantity     int
+	Price            int64
+	Currency         string // the ticket's
+	Status           string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	// associations
+	Passes []ListingPass // has many
+}
+
+// Available is how many units buyers can still order.
+func (l *Listing) Available() int {
+	return l.Quantity - l.ReservedQuantity - l.SoldQuantity
+}
+
+// gorm model
+type ListingPass struct {
+	ID        uuid.UUID
+	ListingId uuid.UUID
+	PassId    uuid.UUID
+	Sold      bool
+	CreatedAt time.Time
+}
+
+// gorm model
+//
+// A ResaleSale records units of a listing bought through a payment, and what
+// the seller is owed for them after the platform fee.
+type ResaleSale struct {
+	ID        uuid.UUID
+	ListingId uuid.UUID
+	PaymentId uuid.UUID // the buyer's payment
+	SellerId  uuid.UUID
+	BuyerId   uuid.UUID
+	Quantity  int
+	Amount    int64
+	Fee       int64
+	Proceeds  int64
+	CreatedAt time.Time
+}
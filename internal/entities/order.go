@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	OrderPending   = "pending"
+	OrderConfirmed = "confirmed"
+	OrderExpired   = "expired"
+	OrderCanceled  = "canceled"
+)
+
+// gorm model
+type Order struct {
+	ID        uuid.UUID
+	UserId    uuid.UUID
+	ActedBy   uuid.NullUUID // admin who placed the order on behalf of UserId
+	TicketId  uuid.UUID
+	ListingId uuid.NullUUID // set when buying from a resale listing
+	// AccessCodeId is the code that unlocked a hidden ticket
+	AccessCodeId uuid.NullUUID
+	Quantity     int
+	Amount       int64
+	Currency     string // the ticket's, amounts never mix currencies
+	Status       string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	// associations
+	User   *User   // belongs to
+	Ticket *Ticket // belongs to
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	PassValid = "valid"
+	PassUsed  = "used"
+	PassVoid  = "void"
+)
+
+// gorm model
+//
+// A Pass is the admission for one unit of a confirmed payment.
+type Pass struct {
+	ID          uuid.UUID
+	PaymentId   uuid.UUID
+	TicketId    uuid.UUID
+	EventId     uuid.UUID
+	UserId      uuid.UUID // current holder
+	Code        string
+	Status      string
+	CheckedInAt sql.NullTime
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// associations
+	Payment *Payment // belongs to
+	Ticket  *Ticket  // belongs to
+	Event   *Event   // belongs to
+}
@@ -10,18 +10,52 @@ var (
 	PaymentPending   = "pending"
 	PaymentConfirmed = "confirmed"
 	PaymentCanceled  = "canceled"
+
+	PaymentRefunded          = "refunded"
+	PaymentPartiallyRefunded = "partially_refunded"
 )
 
 type Payment struct {
 	ID         uuid.UUID
 	UserId     uuid.UUID
+	ActedBy    uuid.NullUUID // admin who paid on behalf of UserId
 	TicketId   uuid.UUID
+	OrderId    uuid.UUID
 	Quantity   int
 	PaidAmount int64
 	Status     string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	// Currency is the order's, every amount of the payment is in it
+	Currency string
+	// PaidAmount is FaceAmount less DiscountAmount, plus TaxAmount, plus
+	// FeeAmount unless the organizer absorbed the fee
+	FaceAmount     int64
+	DiscountCodeId uuid.NullUUID
+	DiscountAmount int64
+	FeeAmount      int64
+	FeeAbsorbed    bool
+	TaxAmount      int64
+	// portion of the payment handed back through refunds
+	RefundedQuantity int
+	RefundedAmount   int64
+	// units sold on through the resale marketplace
+	ResoldQuantity int
+	// units handed to others through transfers; each recipient's share is a
+	// payment of its own, split off from the one in TransferredFrom
+	TransferredQuantity int
+	TransferredFrom     uuid.NullUUID
+	// payment processor that handled the charge and its reference
+	Provider    string
+	ProviderRef string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 	// associations
 	User   *User   // belongs to
 	Ticket *Ticket // belongs to
+	Order  *Order  // belongs to
+}
+
+// RefundableQuantity is how many units are neither refunded, resold nor
+// transferred.
+func (p *Payment) RefundableQuantity() int {
+	return p.Quantity - p.RefundedQuantity - p.ResoldQuantity - p.TransferredQuantity
 }
@@ -15,4 +15,4 @@ type RefreshToken struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	// associations
 	User User `json:"user"`
-}
\ No newline at end of file
+}
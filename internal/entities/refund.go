@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+type Refund struct {
+	ID        uuid.UUID
+	PaymentId uuid.UUID
+	UserId    uuid.NullUUID // who issued the refund, null when the provider did
+	Quantity  int
+	Amount    int64
+	Reason    string
+	// ProviderRef is the gateway's id of the refund, empty until it is issued
+	ProviderRef string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// associations
+	Payment *Payment // belongs to
+}
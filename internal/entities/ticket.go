@@ -1,21 +1,53 @@
 package entities
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	TicketPublic = "public"
+	TicketHidden = "hidden"
+)
+
+var TicketVisibilities = []string{TicketPublic, TicketHidden}
+
 // gorm model
 type Ticket struct {
 	ID                  uuid.UUID
 	EventId             uuid.UUID
 	UserId              uuid.UUID
-	Price               int64
+	Name                string
+	Description         sql.NullString
+	Price               int64  // in the minor unit of Currency
+	Currency            string // ISO 4217
 	TotalQuantities     int
 	RemainingQuantities int
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	ReservedQuantities  int
+	// sale window, open-ended on a null side
+	SalesStart  sql.NullTime
+	SalesEnd    sql.NullTime
+	MinPerOrder int
+	MaxPerOrder int // zero means no limit
+	MaxPerUser  int // zero means no limit
+	Visibility  string
+	// TransfersEnabled lets holders hand passes of this ticket to others
+	TransfersEnabled bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 	// associations
 	Event Event
 }
+
+// OnSale reports whether the ticket's sale window contains now.
+func (t *Ticket) OnSale(now time.Time) bool {
+	if t.SalesStart.Valid && now.Before(t.SalesStart.Time) {
+		return false
+	}
+	if t.SalesEnd.Valid && !now.Before(t.SalesEnd.Time) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	TransferPending  = "pending"
+	TransferAccepted = "accepted"
+	TransferCanceled = "canceled"
+)
+
+// gorm model
+//
+// A Transfer hands passes from their holder to whoever owns RecipientEmail.
+// Together with its TransferPasses it is the ownership history of a pass.
+type Transfer struct {
+	ID             uuid.UUID
+	UserId         uuid.UUID     // sender
+	RecipientId    uuid.NullUUID // set once accepted
+	RecipientEmail string
+	TicketId       uuid.UUID
+	Quantity       int
+	Status         string
+	TokenHash      string
+	ExpiresAt      time.Time
+	AcceptedAt     sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	// associations
+	Passes []TransferPass // has many
+}
+
+// Expired reports whether a pending transfer can no longer be accepted.
+func (t *Transfer) Expired(now time.Time) bool {
+	return t.Status == TransferPending && !now.Before(t.ExpiresAt)
+}
+
+// gorm model
+type TransferPass struct {
+	ID         uuid.UUID
+	TransferId uuid.UUID
+	PassId     uuid.UUID
+	CreatedAt  time.Time
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// gorm model
+type Venue struct {
+	ID     uuid.UUID
+	UserId uuid.UUID // who added the venue
+	Name   string
+	// address
+	AddressLine1 string
+	AddressLine2 sql.NullString
+	City         string
+	Region       sql.NullString
+	PostalCode   sql.NullString
+	Country      string // ISO 3166-1 alpha-2
+	Latitude     float64
+	Longitude    float64
+	// Timezone is an IANA name, such as Europe/Berlin
+	Timezone string
+	// MaxCapacity caps the units of all tickets to an event at the venue
+	MaxCapacity int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistPurchased = "purchased"
+	WaitlistLapsed    = "lapsed" // the offer expired or was canceled
+	WaitlistLeft      = "left"
+)
+
+// gorm model
+//
+// A WaitlistEntry queues a user for a sold out ticket. Once units free up the
+// entry is offered an order holding them.
+type WaitlistEntry struct {
+	ID        uuid.UUID
+	TicketId  uuid.UUID
+	UserId    uuid.UUID
+	Quantity  int
+	Status    string
+	OrderId   uuid.NullUUID // the order offered to the user
+	OfferedAt sql.NullTime
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// associations
+	Order *Order // belongs to
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	WebhookReceived  = "received"
+	WebhookProcessed = "processed"
+	WebhookIgnored   = "ignored"
+	WebhookFailed    = "failed"
+)
+
+// gorm model
+type WebhookEvent struct {
+	ID              uuid.UUID
+	Provider        string
+	ProviderEventId string
+	Type            string
+	PaymentId       uuid.NullUUID
+	Payload         []byte
+	Status          string
+	Error           string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
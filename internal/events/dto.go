@@ -1,9 +1,12 @@
 package events
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/currency"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
 )
@@ -17,11 +20,27 @@ func EventEntitiesToEventResponse(events []entities.Event) []EventResponseDTO {
 }
 
 type EventCreateDTO struct {
-	Title       string    `json:"title" binding:"required"`
-	Description *string   `json:"description"`
-	Venue       string    `json:"venue" binding:"required"`
-	StartTime   time.Time `json:"start_time" binding:"required"`
-	EndTime     time.Time `json:"end_time" binding:"required"`
+	Title             string    `json:"title" binding:"required"`
+	Description       *string   `json:"description"`
+	Venue             string    `json:"venue"`
+	StartTime         time.Time `json:"start_time" binding:"required"`
+	EndTime           time.Time `json:"end_time" binding:"required"`
+	RefundPolicy      *string   `json:"refund_policy"`
+	MaxTicketsPerUser *int      `json:"max_tickets_per_user"`
+	ResaleEnabled     *bool     `json:"resale_enabled"`
+	// Capacity caps units sold across all tickets, zero means no limit. It
+	// may not exceed the venue's capacity
+	Capacity *int `json:"capacity"`
+	// ResaleMaxMarkup caps resale prices, in percent over face value
+	ResaleMaxMarkup *int `json:"resale_max_markup"`
+	// FeeMode defaults to pass_through
+	FeeMode *string `json:"fee_mode"`
+	// TaxRate is in basis points, 825 charges 8.25%
+	TaxRate *int `json:"tax_rate"`
+	// Currency is an ISO 4217 code, defaults to DEFAULT_CURRENCY
+	Currency *string `json:"currency"`
+	// VenueId picks a known venue instead of naming one in venue
+	VenueId *uuid.UUID `json:"venue_id"`
 }
 
 func (e *EventCreateDTO) Validate() utils.ValidationErrors {
@@ -31,50 +50,294 @@ func (e *EventCreateDTO) Validate() utils.ValidationErrors {
 	if e.Description != nil {
 		validator.Must(len(*e.Description) >= 2 && len(*e.Description) <= 1024, "description", "title must be between 2 and 1024 characters")
 	}
-	validator.Must(len(e.Venue) >= 2 && len(e.Venue) <= 255, "title", "title must be between 2 and 255 characters")
+	if e.VenueId == nil {
+		validator.Must(len(e.Venue) >= 2 && len(e.Venue) <= 255, "venue", "venue must be between 2 and 255 characters")
+	}
 	// start time, end time
 	validator.Must(e.StartTime.After(time.Now()), "start_time", "start_time should be in future")
 	validator.Must(e.EndTime.After(time.Now()), "end_time", "end_time should be in future")
 	validator.Must(e.EndTime.After(e.StartTime), "end_time", "end_time should be after start_time ")
+	if e.RefundPolicy != nil {
+		validator.In(*e.RefundPolicy, entities.RefundPolicies, "refund_policy", "refund_policy must be one of none, before_start, anytime")
+	}
+	if e.MaxTicketsPerUser != nil {
+		validator.Must(*e.MaxTicketsPerUser >= 0, "max_tickets_per_user", "max_tickets_per_user must not be negative")
+	}
+	if e.Capacity != nil {
+		validator.Must(*e.Capacity >= 0, "capacity", "capacity must not be negative")
+	}
+	if e.ResaleMaxMarkup != nil {
+		validator.Must(*e.ResaleMaxMarkup >= 0 && *e.ResaleMaxMarkup <= 1000, "resale_max_markup", "resale_max_markup must be between 0 and 1000")
+	}
+	if e.FeeMode != nil {
+		validator.In(*e.FeeMode, entities.FeeModes, "fee_mode", "fee_mode must be one of pass_through, absorbed")
+	}
+	if e.TaxRate != nil {
+		validator.Must(*e.TaxRate >= 0 && *e.TaxRate <= 10000, "tax_rate", "tax_rate must be between 0 and 10000")
+	}
+	if e.Currency != nil {
+		validator.Must(currency.Valid(*e.Currency), "currency", "currency must be a supported ISO 4217 code")
+	}
+
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
 
+var (
+	SortStartTime  = "start_time"
+	SortPrice      = "price"
+	SortPopularity = "popularity"
+	SortCreatedAt  = "created_at"
+	SortDistance   = "distance"
+)
+
+var EventSorts = []string{SortStartTime, SortPrice, SortPopularity, SortCreatedAt, SortDistance}
+
+const (
+	defaultRadiusKm = 25.0
+	maxRadiusKm     = 500.0
+)
+
+// EventSearchQuery filters and orders GET /events. Price and availability
+// look at the event's public tickets, and price bounds need a currency.
+// Without sort, matches of q come most relevant first and other listings
+// soonest first. Near, as "lat,lng", keeps events at venues within RadiusKm
+// of the point.
+type EventSearchQuery struct {
+	Q           string     `form:"q"`
+	From        *time.Time `form:"from"`
+	To          *time.Time `form:"to"`
+	Venue       string     `form:"venue"`
+	OrganizerId string     `form:"organizer_id"`
+	MinPrice    *int64     `form:"min_price"`
+	MaxPrice    *int64     `form:"max_price"`
+	Currency    string     `form:"currency"`
+	Available   *bool      `form:"available"`
+	Sort        string     `form:"sort"`
+	Direction   string     `form:"direction"`
+	Near        string     `form:"near"`
+	RadiusKm    *float64   `form:"radius_km"`
+
+	// lat and lng are parsed from Near by Validate
+	lat, lng float64
+}
+
+// parsePoint reads a "lat,lng" pair in degrees.
+func parsePoint(s string) (lat, lng float64, ok bool) {
+	latText, lngText, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// radius is the search radius around Near, in kilometers.
+func (q *EventSearchQuery) radius() float64 {
+	if q.RadiusKm == nil {
+		return defaultRadiusKm
+	}
+	return *q.RadiusKm
+}
+
+func (q *EventSearchQuery) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	validator.Must(len(q.Q) <= 200, "q", "q must be at most 200 characters")
+	validator.Must(len(q.Venue) <= 255, "venue", "venue must be at most 255 characters")
+	if q.From != nil && q.To != nil {
+		validator.Must(q.To.After(*q.From), "to", "to should be after from")
+	}
+	if q.OrganizerId != "" {
+		_, err := uuid.Parse(q.OrganizerId)
+		validator.Must(err == nil, "organizer_id", "organizer_id must be a UUID")
+	}
+	if q.MinPrice != nil {
+		validator.Must(*q.MinPrice >= 0, "min_price", "min_price must not be negative")
+	}
+	if q.MaxPrice != nil {
+		validator.Must(*q.MaxPrice >= 0, "max_price", "max_price must not be negative")
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil {
+		validator.Must(*q.MaxPrice >= *q.MinPrice, "max_price", "max_price should be at least min_price")
+	}
+	if q.Currency != "" {
+		validator.Must(currency.Valid(q.Currency), "currency", "currency must be a supported ISO 4217 code")
+	} else if q.MinPrice != nil || q.MaxPrice != nil {
+		// prices in different currencies can't be compared
+		validator.Must(false, "currency", "currency is required with min_price or max_price")
+	}
+	if q.Near != "" {
+		lat, lng, ok := parsePoint(q.Near)
+		validator.Must(ok, "near", "near must be a lat,lng pair of coordinates")
+		q.lat, q.lng = lat, lng
+	}
+	if q.RadiusKm != nil {
+		validator.Must(q.Near != "", "radius_km", "radius_km needs near")
+		validator.Must(*q.RadiusKm > 0 && *q.RadiusKm <= maxRadiusKm, "radius_km", "radius_km must be above 0 and at most 500")
+	}
+	if q.Sort != "" {
+		validator.In(q.Sort, EventSorts, "sort", "sort must be one of start_time, price, popularity, created_at, distance")
+		if q.Sort == SortDistance {
+			validator.Must(q.Near != "", "sort", "sort by distance needs near")
+		}
+	}
+	if q.Direction != "" {
+		validator.In(q.Direction, []string{"asc", "desc"}, "direction", "direction must be one of asc, desc")
+	}
 	if !validator.Valid() {
 		return validator.Errors
 	}
 	return nil
 }
 
+// EventUpdateDTO changes the fields it sets. Currency is fixed once the event
+// is created, as tickets are priced in it.
 type EventUpdateDTO struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	Venue       *string    `json:"venue"`
-	StartTime   *time.Time `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
+	Title             *string    `json:"title"`
+	Description       *string    `json:"description"`
+	Venue             *string    `json:"venue"`
+	StartTime         *time.Time `json:"start_time"`
+	EndTime           *time.Time `json:"end_time"`
+	RefundPolicy      *string    `json:"refund_policy"`
+	MaxTicketsPerUser *int       `json:"max_tickets_per_user"`
+	ResaleEnabled     *bool      `json:"resale_enabled"`
+	// Capacity may not go below the units already sold or held
+	Capacity        *int    `json:"capacity"`
+	ResaleMaxMarkup *int    `json:"resale_max_markup"`
+	FeeMode         *string `json:"fee_mode"`
+	TaxRate         *int    `json:"tax_rate"`
+	// VenueId moves the event to a known venue, setting venue alone leaves it
+	VenueId *uuid.UUID `json:"venue_id"`
+}
+
+func (e *EventUpdateDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+
+	if e.Title != nil {
+		validator.Must(len(*e.Title) >= 2 && len(*e.Title) <= 255, "title", "title must be between 2 and 255 characters")
+	}
+	if e.Description != nil {
+		validator.Must(len(*e.Description) >= 2 && len(*e.Description) <= 1024, "description", "description must be between 2 and 1024 characters")
+	}
+	if e.Venue != nil {
+		validator.Must(len(*e.Venue) >= 2 && len(*e.Venue) <= 255, "venue", "venue must be between 2 and 255 characters")
+	}
+	// the other end of the schedule is checked against the stored event
+	if e.StartTime != nil {
+		validator.Must(e.StartTime.After(time.Now()), "start_time", "start_time should be in future")
+	}
+	if e.EndTime != nil {
+		validator.Must(e.EndTime.After(time.Now()), "end_time", "end_time should be in future")
+	}
+	if e.StartTime != nil && e.EndTime != nil {
+		validator.Must(e.EndTime.After(*e.StartTime), "end_time", "end_time should be after start_time ")
+	}
+	if e.RefundPolicy != nil {
+		validator.In(*e.RefundPolicy, entities.RefundPolicies, "refund_policy", "refund_policy must be one of none, before_start, anytime")
+	}
+	if e.MaxTicketsPerUser != nil {
+		validator.Must(*e.MaxTicketsPerUser >= 0, "max_tickets_per_user", "max_tickets_per_user must not be negative")
+	}
+	if e.Capacity != nil {
+		validator.Must(*e.Capacity >= 0, "capacity", "capacity must not be negative")
+	}
+	if e.ResaleMaxMarkup != nil {
+		validator.Must(*e.ResaleMaxMarkup >= 0 && *e.ResaleMaxMarkup <= 1000, "resale_max_markup", "resale_max_markup must be between 0 and 1000")
+	}
+	if e.FeeMode != nil {
+		validator.In(*e.FeeMode, entities.FeeModes, "fee_mode", "fee_mode must be one of pass_through, absorbed")
+	}
+	if e.TaxRate != nil {
+		validator.Must(*e.TaxRate >= 0 && *e.TaxRate <= 10000, "tax_rate", "tax_rate must be between 0 and 10000")
+	}
+
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+type EventChangeResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Notify    bool      `json:"notify"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func EventChangeEntitiesToEventChangeResponse(changes []entities.EventChange) []EventChangeResponseDTO {
+	result := make([]EventChangeResponseDTO, len(changes))
+	for i, c := range changes {
+		result[i] = EventChangeResponseDTO{
+			ID:        c.ID,
+			Field:     c.Field,
+			OldValue:  c.OldValue,
+			NewValue:  c.NewValue,
+			Notify:    c.Notify,
+			CreatedAt: c.CreatedAt,
+		}
+	}
+	return result
 }
 
 type EventResponseDTO struct {
-	ID          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description,omitempty"`
-	Venue       string    `json:"venue"`
-	UserId      uuid.UUID `json:"user_id"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uuid.UUID  `json:"id"`
+	Title             string     `json:"title"`
+	Description       string     `json:"description,omitempty"`
+	Venue             string     `json:"venue"`
+	VenueId           *uuid.UUID `json:"venue_id,omitempty"`
+	UserId            uuid.UUID  `json:"user_id"`
+	StartTime         time.Time  `json:"start_time"`
+	EndTime           time.Time  `json:"end_time"`
+	Status            string     `json:"status"`
+	RefundPolicy      string     `json:"refund_policy"`
+	MaxTicketsPerUser int        `json:"max_tickets_per_user,omitempty"`
+	Capacity          int        `json:"capacity,omitempty"`
+	ResaleEnabled     bool       `json:"resale_enabled"`
+	ResaleMaxMarkup   int        `json:"resale_max_markup"`
+	FeeMode           string     `json:"fee_mode"`
+	TaxRate           int        `json:"tax_rate"`
+	Currency          string     `json:"currency"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 func EventEntityToEventResponse(e *entities.Event) EventResponseDTO {
-	return EventResponseDTO{
-		ID:          e.ID,
-		Title:       e.Title,
-		Description: e.Description.String,
-		Venue:       e.Venue,
-		UserId:      e.UserId,
-		StartTime:   e.StartTime,
-		EndTime:     e.EndTime,
-		CreatedAt:   e.CreatedAt,
-		UpdatedAt:   e.UpdatedAt,
+	event := EventResponseDTO{
+		ID:                e.ID,
+		Title:             e.Title,
+		Description:       e.Description.String,
+		Venue:             e.Venue,
+		UserId:            e.UserId,
+		StartTime:         e.StartTime,
+		EndTime:           e.EndTime,
+		Status:            e.Status,
+		RefundPolicy:      e.RefundPolicy,
+		MaxTicketsPerUser: e.MaxTicketsPerUser,
+		Capacity:          e.Capacity,
+		ResaleEnabled:     e.ResaleEnabled,
+		ResaleMaxMarkup:   e.ResaleMaxMarkup,
+		FeeMode:           e.FeeMode,
+		TaxRate:           e.TaxRate,
+		Currency:          e.Currency,
+		CreatedAt:         e.CreatedAt,
+		UpdatedAt:         e.UpdatedAt,
+	}
+	if e.VenueId.Valid {
+		venueId := e.VenueId.UUID
+		event.VenueId = &venueId
 	}
+	return event
 }
 
 // -------------------------------------------------------- //
@@ -6,8 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/currency"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
 	"gorm.io/gorm"
 )
 
@@ -31,16 +33,62 @@ func (service *EventsService) CreateEventHandler(c *gin.Context) {
 	}
 
 	event := &entities.Event{
-		Title:     input.Title,
-		Venue:     input.Venue,
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
-		UserId:    userId,
+		Title:        input.Title,
+		Venue:        input.Venue,
+		StartTime:    input.StartTime,
+		EndTime:      input.EndTime,
+		UserId:       userId,
+		Status:       entities.EventDraft,
+		RefundPolicy: entities.RefundPolicyBeforeStart,
+		FeeMode:      entities.FeeModePassThrough,
+		Currency:     currency.Default(),
 	}
 	if input.Description != nil {
 		event.Description.Valid = true
 		event.Description.String = *input.Description
 	}
+	if input.RefundPolicy != nil {
+		event.RefundPolicy = *input.RefundPolicy
+	}
+	if input.MaxTicketsPerUser != nil {
+		event.MaxTicketsPerUser = *input.MaxTicketsPerUser
+	}
+	if input.Capacity != nil {
+		event.Capacity = *input.Capacity
+	}
+	if input.ResaleEnabled != nil {
+		event.ResaleEnabled = *input.ResaleEnabled
+	}
+	if input.ResaleMaxMarkup != nil {
+		event.ResaleMaxMarkup = *input.ResaleMaxMarkup
+	}
+	if input.FeeMode != nil {
+		event.FeeMode = *input.FeeMode
+	}
+	if input.TaxRate != nil {
+		event.TaxRate = *input.TaxRate
+	}
+	if input.Currency != nil {
+		event.Currency = utils.NormalizeCode(*input.Currency)
+	}
+	if input.VenueId != nil {
+		venue, err := service.getVenue(c.Request.Context(), *input.VenueId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+				return
+			}
+			service.logger.Error("failed getting venue", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if event.Capacity > venue.MaxCapacity {
+			c.JSON(http.StatusConflict, gin.H{"error": venues.ErrOverCapacity.Error()})
+			return
+		}
+		event.VenueId = uuid.NullUUID{UUID: venue.ID, Valid: true}
+		event.Venue = venue.Name
+	}
 
 	err := service.createEvent(c.Request.Context(), event)
 	if err != nil {
@@ -74,30 +122,51 @@ func (service *EventsService) GetEventHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
+	userIdAny, _ := c.Get("user_id")
+	userId, _ := userIdAny.(uuid.UUID)
+	if !event.VisibleTo(userId, c.GetString("user_role")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+	c.Header("ETag", ETag(event))
 
 	c.JSON(http.StatusOK, EventEntityToEventResponse(event))
 
 }
 
 func (service *EventsService) GetEventsHandler(c *gin.Context) {
-	var p utils.Pagination
+	var p utils.CursorPagination
 
 	if err := c.ShouldBindQuery(&p); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
 		return
 	}
 
-	events, total, err := service.getEvents(c.Request.Context(), &p)
+	var q EventSearchQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := q.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	events, page, err := service.getEvents(c.Request.Context(), &q, &p)
 	if err != nil {
-		service.logger.Error("failed to get users", "page", p.Page, "limit", p.PageSize, "error", err)
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get events", "limit", p.PageSize, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"data":      EventEntitiesToEventResponse(events),
-		"total":     total,
-		"page":      p.Page,
-		"page_size": p.PageSize,
+		"data":        EventEntitiesToEventResponse(events),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
 	})
 }
 
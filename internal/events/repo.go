@@ -2,10 +2,12 @@ package events
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
 	"gorm.io/gorm"
 )
 
@@ -27,6 +29,14 @@ func (service *EventsService) getEvent(ctx context.Context, eventId uuid.UUID) (
 	return &event, nil
 }
 
+func (service *EventsService) getVenue(ctx context.Context, venueId uuid.UUID) (*entities.Venue, error) {
+	venue, err := gorm.G[entities.Venue](service.db).Where("id = ?", venueId).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
 func (service *EventsService) deleteEvent(ctx context.Context, eventId uuid.UUID) error {
 	rowsAffected, err := gorm.G[entities.Event](service.db).Where("id = ?", eventId).Delete(ctx)
 	if rowsAffected == 0 {
@@ -37,15 +47,118 @@ func (service *EventsService) deleteEvent(ctx context.Context, eventId uuid.UUID
 	return nil
 }
 
-func (service *EventsService) getEvents(ctx context.Context, p *utils.Pagination) ([]entities.Event, int64, error) {
-	var total int64
-	if res := service.db.Model(&entities.Event{}).Count(&total); res.Error != nil {
-		return nil, 0, res.Error
-	}
+// getEvents lists the events matching q, in the order it asks for.
+func (service *EventsService) getEvents(ctx context.Context, q *EventSearchQuery, p *utils.CursorPagination) ([]entities.Event, utils.Page, error) {
+	db := service.db.WithContext(ctx).Model(&entities.Event{}).Scopes(q.filter)
+
 	var events []entities.Event
-	err := service.db.Scopes(p.Paginate).Find(&events).Error
+	page, err := utils.CursorPaginate(db, p, q.keyset(), func(e *entities.Event) uuid.UUID { return e.ID }, &events)
 	if err != nil {
-		return nil, 0, err
+		return nil, page, err
+	}
+	return events, page, nil
+}
+
+// publicTickets is the condition matching an event's public tickets in the
+// subqueries below.
+const publicTickets = "tickets.event_id = events.id AND tickets.visibility = 'public'"
+
+func (q *EventSearchQuery) filter(db *gorm.DB) *gorm.DB {
+	db = db.Where("events.status = ?", entities.EventPublished)
+	if q.Q != "" {
+		db = db.Where("events.search @@ websearch_to_tsquery('english', ?)", q.Q)
+	}
+	if q.From != nil {
+		db = db.Where("events.start_time >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("events.start_time < ?", *q.To)
+	}
+	if q.Venue != "" {
+		db = db.Where("events.venue ILIKE ?", "%"+utils.EscapeLike(q.Venue)+"%")
+	}
+	if q.OrganizerId != "" {
+		db = db.Where("events.user_id = ?", q.OrganizerId)
+	}
+	if q.Currency != "" {
+		db = db.Where("events.currency = ?", utils.NormalizeCode(q.Currency))
+	}
+	if q.Near != "" {
+		db = db.Where("events.venue_id IN (?)", venues.Within(db.Session(&gorm.Session{NewDB: true}), q.lat, q.lng, q.radius()))
+	}
+
+	if q.MinPrice != nil || q.MaxPrice != nil {
+		tickets := "SELECT 1 FROM tickets WHERE " + publicTickets
+		var args []any
+		if q.MinPrice != nil {
+			tickets += " AND tickets.price >= ?"
+			args = append(args, *q.MinPrice)
+		}
+		if q.MaxPrice != nil {
+			tickets += " AND tickets.price <= ?"
+			args = append(args, *q.MaxPrice)
+		}
+		db = db.Where("EXISTS ("+tickets+")", args...)
+	}
+	if q.Available != nil {
+		available := "EXISTS (SELECT 1 FROM tickets WHERE " + publicTickets + " AND tickets.remaining_quantities > 0)"
+		if !*q.Available {
+			available = "NOT " + available
+		}
+		db = db.Where(available)
+	}
+	return db
+}
+
+// keyset is the order q asks for. Sort keys are never null, so events
+// without public tickets are priced past either end of the range instead.
+func (q *EventSearchQuery) keyset() utils.Keyset {
+	if q.Sort == "" && q.Q != "" {
+		// ranks depend on the query, which is part of the name for that reason
+		return utils.Keyset{
+			Name:   "relevance_" + q.Q,
+			Table:  "events",
+			Column: "ts_rank(events.search, websearch_to_tsquery('english', ?))",
+			Vars:   []any{q.Q},
+			Desc:   true,
+		}
+	}
+
+	sort, direction := q.Sort, q.Direction
+	if sort == "" {
+		sort = SortStartTime
+	}
+	if direction == "" {
+		direction = "asc"
+		if sort == SortPopularity || sort == SortCreatedAt {
+			direction = "desc"
+		}
+	}
+	// both are checked against fixed lists by Validate
+	var column string
+	switch sort {
+	case SortDistance:
+		// the point is part of the name, so cursors only work for the search they came from
+		near := strconv.FormatFloat(q.lat, 'f', -1, 64) + "," + strconv.FormatFloat(q.lng, 'f', -1, 64)
+		return utils.Keyset{
+			Name:   sort + "_" + direction + "_" + near,
+			Table:  "events",
+			Column: "(SELECT " + venues.DistanceSQL + " FROM venues WHERE venues.id = events.venue_id)",
+			Vars:   venues.DistanceVars(q.lat, q.lng),
+			Desc:   direction == "desc",
+		}
+	case SortPrice:
+		unpriced := "9223372036854775807"
+		if direction == "desc" {
+			unpriced = "-1"
+		}
+		column = "COALESCE((SELECT MIN(tickets.price) FROM tickets WHERE " + publicTickets + "), " + unpriced + ")"
+	case SortPopularity:
+		column = "(SELECT COALESCE(SUM(tickets.total_quantities - tickets.remaining_quantities), 0) FROM tickets WHERE tickets.event_id = events.id)"
+	case SortCreatedAt:
+		column = "events.created_at"
+	default:
+		column = "events.start_time"
 	}
-	return events, total, nil
+	return utils.Keyset{Name: sort + "_" + direction, Table: "events", Column: column, Desc: direction == "desc"}
 }
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAlreadyStaff = errors.New("user is already staff for this event")
+
+type EventStaffCreateDTO struct {
+	UserId uuid.UUID `json:"user_id" binding:"required"`
+}
+
+type EventStaffResponseDTO struct {
+	UserId    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func EventStaffEntitiesToEventStaffResponse(staff []entities.EventStaff) []EventStaffResponseDTO {
+	result := make([]EventStaffResponseDTO, 0, len(staff))
+	for _, s := range staff {
+		dto := EventStaffResponseDTO{UserId: s.UserId, CreatedAt: s.CreatedAt}
+		if s.User != nil {
+			dto.Name = s.User.Name
+			dto.Email = s.User.Email
+		}
+		result = append(result, dto)
+	}
+	return result
+}
+
+func (service *EventsService) addEventStaff(ctx context.Context, eventId, userId uuid.UUID) (*entities.EventStaff, error) {
+	staff := &entities.EventStaff{ID: uuid.New(), EventId: eventId, UserId: userId}
+	res := service.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(staff)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrAlreadyStaff
+	}
+	return staff, nil
+}
+
+func (service *EventsService) getEventStaff(ctx context.Context, eventId uuid.UUID) ([]entities.EventStaff, error) {
+	var staff []entities.EventStaff
+	err := service.db.WithContext(ctx).Preload("User").Where("event_id = ?", eventId).Order("created_at ASC").Find(&staff).Error
+	return staff, err
+}
+
+func (service *EventsService) removeEventStaff(ctx context.Context, eventId, userId uuid.UUID) error {
+	res := service.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventId, userId).Delete(&entities.EventStaff{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (service *EventsService) AddEventStaffHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	var input EventStaffCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	staff, err := service.addEventStaff(c.Request.Context(), eventId, input.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAlreadyStaff):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrForeignKeyViolated):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			service.logger.Error("failed adding event staff", "eventId", eventId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, EventStaffResponseDTO{UserId: staff.UserId, CreatedAt: staff.CreatedAt})
+}
+
+func (service *EventsService) GetEventStaffHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	staff, err := service.getEventStaff(c.Request.Context(), eventId)
+	if err != nil {
+		service.logger.Error("failed retrieving event staff", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": EventStaffEntitiesToEventStaffResponse(staff)})
+}
+
+func (service *EventsService) RemoveEventStaffHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "staff member not found"})
+		return
+	}
+
+	if err := service.removeEventStaff(c.Request.Context(), eventId, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "staff member not found"})
+			return
+		}
+		service.logger.Error("failed removing event staff", "eventId", eventId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidTransition = errors.New("event status transition not allowed")
+	ErrEventEnded        = errors.New("event has already ended")
+)
+
+// completeBatchSize bounds how many ended events a single pass completes.
+const completeBatchSize = 100
+
+// Transition locks an event and moves it to status, so flows cascading from
+// the change, such as canceling the event's payments, can run in the same
+// transaction.
+func Transition(tx *gorm.DB, eventId uuid.UUID, status string) (*entities.Event, error) {
+	var event entities.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", eventId).First(&event).Error; err != nil {
+		return nil, err
+	}
+	if !event.CanTransition(status) {
+		return nil, ErrInvalidTransition
+	}
+	// republishing a postponed event needs it rescheduled first
+	if status == entities.EventPublished && !event.EndTime.After(time.Now()) {
+		return nil, ErrEventEnded
+	}
+	event.Status = status
+	if err := tx.Model(&event).Update("status", status).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (service *EventsService) transitionEvent(ctx context.Context, eventId uuid.UUID, status string) (*entities.Event, error) {
+	var event *entities.Event
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		event, err = Transition(tx, eventId, status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// completeEndedEvents moves published events whose end time has passed to
+// completed.
+func (service *EventsService) completeEndedEvents(ctx context.Context) (int64, error) {
+	ended := service.db.Model(&entities.Event{}).
+		Select("id").
+		Where("status = ? AND end_time < ?", entities.EventPublished, time.Now()).
+		Order("end_time ASC").
+		Limit(completeBatchSize)
+	res := service.db.WithContext(ctx).Model(&entities.Event{}).
+		Where("id IN (?)", ended).
+		Update("status", entities.EventCompleted)
+	return res.RowsAffected, res.Error
+}
+
+// RunCompletion completes events once they have ended, every interval, until
+// ctx is canceled.
+func (service *EventsService) RunCompletion(ctx context.Context, interval time.Duration) {
+	utils.Every(ctx, interval, func(ctx context.Context) {
+		completed, err := service.completeEndedEvents(ctx)
+		if err != nil {
+			service.logger.Error("failed completing ended events", "error", err.Error())
+			return
+		}
+		if completed > 0 {
+			service.logger.Info("completed ended events", "count", completed)
+		}
+	})
+}
+
+func (service *EventsService) PublishEventHandler(c *gin.Context) {
+	service.transitionEventHandler(c, entities.EventPublished)
+}
+
+func (service *EventsService) PostponeEventHandler(c *gin.Context) {
+	service.transitionEventHandler(c, entities.EventPostponed)
+}
+
+func (service *EventsService) transitionEventHandler(c *gin.Context, status string) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	event, err := service.transitionEvent(c.Request.Context(), eventId, status)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrEventEnded):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed changing event status", "eventId", eventId.String(), "status", status, "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, EventEntityToEventResponse(event))
+}
@@ -0,0 +1,298 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrEventModified     = errors.New("event was modified since it was read")
+	ErrIfMatchRequired   = errors.New("If-Match with the event's ETag is required")
+	ErrEventClosed       = errors.New("canceled and completed events can't be changed")
+	ErrInvalidSchedule   = errors.New("end_time should be after start_time")
+	ErrCapacityBelowSold = errors.New("capacity is below the units already sold or held")
+	ErrVenueNotFound     = errors.New("venue not found")
+)
+
+// notifyFields are the changes attendees are told about.
+var notifyFields = map[string]bool{
+	"title":         true,
+	"venue":         true,
+	"start_time":    true,
+	"end_time":      true,
+	"refund_policy": true,
+}
+
+// ETag identifies the version of an event, for If-Match on updates.
+func ETag(event *entities.Event) string {
+	return `"` + strconv.FormatInt(event.UpdatedAt.UnixMicro(), 10) + `"`
+}
+
+// updateEvent applies input to an event, recording every changed field in
+// its history. The event must still be at the version ifMatch names, so
+// concurrent edits can't overwrite each other unseen.
+func (service *EventsService) updateEvent(ctx context.Context, eventId, userId uuid.UUID, ifMatch string, input EventUpdateDTO) (*entities.Event, error) {
+	if ifMatch == "" {
+		return nil, ErrIfMatchRequired
+	}
+	var event entities.Event
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", eventId).First(&event).Error; err != nil {
+			return err
+		}
+		if ifMatch != "*" && ifMatch != ETag(&event) {
+			return ErrEventModified
+		}
+		if event.Status == entities.EventCanceled || event.Status == entities.EventCompleted {
+			return ErrEventClosed
+		}
+
+		before := event
+		if input.Title != nil {
+			event.Title = *input.Title
+		}
+		if input.Description != nil {
+			event.Description.Valid = true
+			event.Description.String = *input.Description
+		}
+		if input.VenueId != nil {
+			var venue entities.Venue
+			if err := tx.Where("id = ?", *input.VenueId).First(&venue).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrVenueNotFound
+				}
+				return err
+			}
+			event.VenueId = uuid.NullUUID{UUID: venue.ID, Valid: true}
+			event.Venue = venue.Name
+		} else if input.Venue != nil {
+			event.VenueId = uuid.NullUUID{}
+			event.Venue = *input.Venue
+		}
+		if input.StartTime != nil {
+			event.StartTime = *input.StartTime
+		}
+		if input.EndTime != nil {
+			event.EndTime = *input.EndTime
+		}
+		if input.RefundPolicy != nil {
+			event.RefundPolicy = *input.RefundPolicy
+		}
+		if input.MaxTicketsPerUser != nil {
+			event.MaxTicketsPerUser = *input.MaxTicketsPerUser
+		}
+		if input.ResaleEnabled != nil {
+			event.ResaleEnabled = *input.ResaleEnabled
+		}
+		if input.ResaleMaxMarkup != nil {
+			event.ResaleMaxMarkup = *input.ResaleMaxMarkup
+		}
+		if input.FeeMode != nil {
+			event.FeeMode = *input.FeeMode
+		}
+		if input.TaxRate != nil {
+			event.TaxRate = *input.TaxRate
+		}
+		if !event.EndTime.After(event.StartTime) {
+			return ErrInvalidSchedule
+		}
+		if input.Capacity != nil {
+			event.Capacity = *input.Capacity
+			if event.Capacity > 0 {
+				var taken int
+				err := tx.Model(&entities.Ticket{}).
+					Select("COALESCE(SUM(total_quantities - remaining_quantities), 0)").
+					Where("event_id = ?", event.ID).
+					Scan(&taken).Error
+				if err != nil {
+					return err
+				}
+				if event.Capacity < taken {
+					return ErrCapacityBelowSold
+				}
+			}
+		}
+		if event.VenueId.Valid && (input.VenueId != nil || input.Capacity != nil) {
+			total, err := venues.TicketTotal(tx, event.ID, uuid.Nil)
+			if err != nil {
+				return err
+			}
+			if err := venues.CheckCapacity(tx, &event, total); err != nil {
+				return err
+			}
+		}
+
+		changes := diffEvent(&before, &event)
+		if len(changes) == 0 {
+			return nil
+		}
+		columns := []string{"updated_at"}
+		for i := range changes {
+			changes[i].ID = uuid.New()
+			changes[i].EventId = event.ID
+			changes[i].UserId = uuid.NullUUID{UUID: userId, Valid: true}
+			changes[i].Notify = notifyFields[changes[i].Field]
+			columns = append(columns, changes[i].Field)
+		}
+		if err := tx.Model(&event).Select(columns).Updates(&event).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&changes).Error; err != nil {
+			return err
+		}
+		// the stored timestamp is what the next If-Match is checked against
+		return tx.Where("id = ?", event.ID).First(&event).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// diffEvent lists the fields that differ between two versions of an event.
+func diffEvent(before, after *entities.Event) []entities.EventChange {
+	var changes []entities.EventChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, entities.EventChange{Field: field, OldValue: from, NewValue: to})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("description", before.Description.String, after.Description.String)
+	add("venue", before.Venue, after.Venue)
+	add("venue_id", nullUUIDString(before.VenueId), nullUUIDString(after.VenueId))
+	add("start_time", before.StartTime.UTC().Format(time.RFC3339), after.StartTime.UTC().Format(time.RFC3339))
+	add("end_time", before.EndTime.UTC().Format(time.RFC3339), after.EndTime.UTC().Format(time.RFC3339))
+	add("refund_policy", before.RefundPolicy, after.RefundPolicy)
+	add("max_tickets_per_user", strconv.Itoa(before.MaxTicketsPerUser), strconv.Itoa(after.MaxTicketsPerUser))
+	add("capacity", strconv.Itoa(before.Capacity), strconv.Itoa(after.Capacity))
+	add("resale_enabled", strconv.FormatBool(before.ResaleEnabled), strconv.FormatBool(after.ResaleEnabled))
+	add("resale_max_markup", strconv.Itoa(before.ResaleMaxMarkup), strconv.Itoa(after.ResaleMaxMarkup))
+	add("fee_mode", before.FeeMode, after.FeeMode)
+	add("tax_rate", strconv.Itoa(before.TaxRate), strconv.Itoa(after.TaxRate))
+	return changes
+}
+
+func nullUUIDString(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
+}
+
+// getEventChanges pages through an event's history, latest first. The
+// history of drafts is as private as the drafts are.
+func (service *EventsService) getEventChanges(ctx context.Context, eventId, userId uuid.UUID, role string, p *utils.CursorPagination) ([]entities.EventChange, utils.Page, error) {
+	event, err := service.getEvent(ctx, eventId)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	if !event.VisibleTo(userId, role) {
+		return nil, utils.Page{}, gorm.ErrRecordNotFound
+	}
+
+	var changes []entities.EventChange
+	keyset := utils.Keyset{Name: "created_at", Table: "event_changes", Column: "event_changes.created_at", Desc: true}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Where("event_id = ?", eventId), p, keyset, func(c *entities.EventChange) uuid.UUID { return c.ID }, &changes)
+	if err != nil {
+		return nil, page, err
+	}
+	return changes, page, nil
+}
+
+func (service *EventsService) UpdateEventHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	var input EventUpdateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	event, err := service.updateEvent(c.Request.Context(), eventId, userId, c.GetHeader("If-Match"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		case errors.Is(err, ErrVenueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrIfMatchRequired):
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrEventModified):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidSchedule):
+			c.JSON(http.StatusBadRequest, gin.H{"errors": utils.ValidationErrors{"end_time": err.Error()}})
+		case errors.Is(err, ErrEventClosed), errors.Is(err, ErrCapacityBelowSold), errors.Is(err, venues.ErrOverCapacity):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed updating event", "eventId", eventId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.Header("ETag", ETag(event))
+	c.JSON(http.StatusOK, EventEntityToEventResponse(event))
+}
+
+func (service *EventsService) GetEventChangesHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, _ := userIdAny.(uuid.UUID)
+	changes, page, err := service.getEventChanges(c.Request.Context(), eventId, userId, c.GetString("user_role"), &p)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get event changes", "eventId", eventId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        EventChangeEntitiesToEventChangeResponse(changes),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// recordingWriter keeps a copy of the response body so it can be replayed.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware makes a route honour the Idempotency-Key header. The first
+// request with a key runs normally and its response is stored; later requests
+// with the same key and body get the stored response replayed, while reusing
+// the key for a different request is rejected. Requests without the header
+// are passed through. On protected routes it must run after
+// auth.AuthMiddleware so keys are scoped per user.
+func (service *IdempotencyService) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed reading request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := hashRequest(c, body)
+		record, created, err := service.reserveKey(c.Request.Context(), scopeOf(c), key, requestHash)
+		if err != nil {
+			service.logger.Error("failed reserving idempotency key", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			c.Abort()
+			return
+		}
+
+		if !created {
+			switch {
+			case record.RequestHash != requestHash:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+			case !record.CompletedAt.Valid:
+				c.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is still in progress"})
+			default:
+				c.Header(HeaderReplayed, "true")
+				c.Data(record.ResponseStatus, record.ResponseContentType, record.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// server errors and panics are not recorded so the request can be retried
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if err := service.releaseKey(context.Background(), record); err != nil {
+				service.logger.Error("failed releasing idempotency key", "error", err.Error())
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		if err := service.completeKey(c.Request.Context(), record, status, writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			service.logger.Error("failed storing idempotent response", "error", err.Error())
+			return
+		}
+		stored = true
+	}
+}
+
+func hashRequest(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func scopeOf(c *gin.Context) string {
+	if userId, ok := c.Get("user_id"); ok {
+		if id, ok := userId.(uuid.UUID); ok {
+			return "user:" + id.String()
+		}
+	}
+	return "anonymous:" + c.ClientIP()
+}
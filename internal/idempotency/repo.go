@@ -0,0 +1,65 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reserveKey claims key within scope for a request. It reports whether the
+// key was newly claimed; otherwise the record of the earlier request is
+// returned.
+func (service *IdempotencyService) reserveKey(ctx context.Context, scope, key, requestHash string) (*entities.IdempotencyKey, bool, error) {
+	var record entities.IdempotencyKey
+	var created bool
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// an expired key is free to be used again
+		if err := tx.Where("scope = ? AND key = ? AND expires_at < ?", scope, key, time.Now()).Delete(&entities.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		record = entities.IdempotencyKey{
+			ID:          uuid.New(),
+			Key:         key,
+			Scope:       scope,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(keyTTL),
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			created = true
+			return nil
+		}
+		return tx.Where("scope = ? AND key = ?", scope, key).First(&record).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &record, created, nil
+}
+
+func (service *IdempotencyService) completeKey(ctx context.Context, record *entities.IdempotencyKey, status int, contentType string, body []byte) error {
+	return service.db.WithContext(ctx).Model(record).Updates(map[string]any{
+		"response_status":       status,
+		"response_content_type": contentType,
+		"response_body":         body,
+		"completed_at":          sql.NullTime{Time: time.Now(), Valid: true},
+	}).Error
+}
+
+// releaseKey forgets a key whose request failed so the client can retry it.
+func (service *IdempotencyService) releaseKey(ctx context.Context, record *entities.IdempotencyKey) error {
+	return service.db.WithContext(ctx).Where("id = ?", record.ID).Delete(&entities.IdempotencyKey{}).Error
+}
+
+func (service *IdempotencyService) deleteExpiredKeys(ctx context.Context) error {
+	return service.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entities.IdempotencyKey{}).Error
+}
@@ -0,0 +1,37 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	keyTTL       = 24 * time.Hour
+)
+
+type IdempotencyService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewIdempotencyService(db *gorm.DB, logger *slog.Logger) *IdempotencyService {
+	return &IdempotencyService{db: db, logger: logger}
+}
+
+// RunCleanup deletes expired idempotency keys every interval until ctx is
+// canceled.
+func (service *IdempotencyService) RunCleanup(ctx context.Context, interval time.Duration) {
+	utils.Every(ctx, interval, func(ctx context.Context) {
+		if err := service.deleteExpiredKeys(ctx); err != nil {
+			service.logger.Error("failed deleting expired idempotency keys", "error", err.Error())
+		}
+	})
+}
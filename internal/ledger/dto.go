@@ -0,0 +1,81 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+// Balance is what a user is owed in one currency.
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+type Payout struct {
+	ID        uuid.UUID `json:"id"`
+	Currency  string    `json:"currency"`
+	Amount    int64     `json:"amount"`
+	Entries   int       `json:"entries"`
+	PeriodEnd time.Time `json:"period_end"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func PayoutEntityToPayout(p *entities.Payout) Payout {
+	return Payout{
+		ID:        p.ID,
+		Currency:  p.Currency,
+		Amount:    p.Amount,
+		Entries:   p.Entries,
+		PeriodEnd: p.PeriodEnd,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func PayoutEntitiesToPayouts(payouts []entities.Payout) []Payout {
+	result := make([]Payout, len(payouts))
+	for i := range payouts {
+		result[i] = PayoutEntityToPayout(&payouts[i])
+	}
+	return result
+}
+
+// ExportQuery bounds a CSV export to the UTC days from through to, both
+// included, by default to the last 30 days.
+type ExportQuery struct {
+	From *time.Time `form:"from" time_format:"2006-01-02"`
+	To   *time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+func (q *ExportQuery) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	if q.From != nil && q.To != nil {
+		v.Must(!q.To.Before(*q.From), "to", "to should not be before from")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+// Range resolves the query to [from, to) at UTC day boundaries, to being the
+// start of the day after the last one exported.
+func (q *ExportQuery) Range(now time.Time) (time.Time, time.Time) {
+	last := utcDay(now)
+	if q.To != nil {
+		last = utcDay(*q.To)
+	}
+	from := last.AddDate(0, 0, -29)
+	if q.From != nil {
+		from = utcDay(*q.From)
+	}
+	return from, last.AddDate(0, 0, 1)
+}
+
+// utcDay returns the start of t's day in UTC.
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
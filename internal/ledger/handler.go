@@ -0,0 +1,132 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+// organizer is whose payouts are shown: the caller, or for admins the user
+// named by ?organizer_id.
+func organizer(c *gin.Context, userId uuid.UUID) (uuid.UUID, error) {
+	if value := c.Query("organizer_id"); value != "" && c.GetString("user_role") == "admin" {
+		return uuid.Parse(value)
+	}
+	return userId, nil
+}
+
+func (service *LedgerService) GetMyPayoutsHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userId, err := organizer(c, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organizer_id"})
+		return
+	}
+
+	payouts, page, err := service.getUserPayouts(c.Request.Context(), userId, &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get payouts", "userId", userId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	unsettled, err := service.getUnsettled(c.Request.Context(), userId)
+	if err != nil {
+		service.logger.Error("failed to get unsettled balance", "userId", userId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        PayoutEntitiesToPayouts(payouts),
+		"unsettled":   unsettled,
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
+
+// ExportPayoutsHandler writes the caller's payable entries as CSV, one row
+// per sale, refund or payout, for reconciliation.
+func (service *LedgerService) ExportPayoutsHandler(c *gin.Context) {
+	var q ExportQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := q.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userId, err := organizer(c, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organizer_id"})
+		return
+	}
+
+	from, to := q.Range(time.Now())
+	entries, err := service.getPayableEntries(c.Request.Context(), userId, from, to)
+	if err != nil {
+		service.logger.Error("failed to get ledger entries", "userId", userId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="payouts-`+from.Format("2006-01-02")+`-`+to.AddDate(0, 0, -1).Format("2006-01-02")+`.csv"`)
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	nullable := func(id uuid.NullUUID) string {
+		if !id.Valid {
+			return ""
+		}
+		return id.UUID.String()
+	}
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"created_at", "transaction_id", "kind", "event_id", "payment_id", "refund_id", "payout_id", "currency", "amount"})
+	for _, e := range entries {
+		// credits to the payable account are money owed, shown positive
+		w.Write([]string{
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.TransactionId.String(),
+			e.Kind,
+			nullable(e.EventId),
+			nullable(e.PaymentId),
+			nullable(e.RefundId),
+			nullable(e.PayoutId),
+			e.Currency,
+			strconv.FormatInt(-e.Amount, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		service.logger.Error("failed writing payouts export", "userId", userId.String(), "error", err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+package ledger
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+// post records legs as one transaction of kind, skipping empty legs.
+func post(tx *gorm.DB, kind string, legs []entities.LedgerEntry) error {
+	transactionId := uuid.New()
+	var sum int64
+	entries := make([]entities.LedgerEntry, 0, len(legs))
+	for _, leg := range legs {
+		if leg.Amount == 0 {
+			continue
+		}
+		leg.ID = uuid.New()
+		leg.TransactionId = transactionId
+		leg.Kind = kind
+		sum += leg.Amount
+		entries = append(entries, leg)
+	}
+	if sum != 0 {
+		return ErrUnbalanced
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// RecordPayment posts a confirmed payment: the amount collected is owed to
+// the event's organizer, less the platform fee. Resold passes are owed to
+// their seller instead, less the resale fee, while the tax on them still
+// goes to the organizer.
+func RecordPayment(tx *gorm.DB, payment *entities.Payment) error {
+	var ticket entities.Ticket
+	if err := tx.Preload("Event").Where("id = ?", payment.TicketId).First(&ticket).Error; err != nil {
+		return err
+	}
+
+	leg := func(account string, userId uuid.NullUUID, amount int64) entities.LedgerEntry {
+		return entities.LedgerEntry{
+			Account:   account,
+			UserId:    userId,
+			EventId:   uuid.NullUUID{UUID: ticket.EventId, Valid: true},
+			PaymentId: uuid.NullUUID{UUID: payment.ID, Valid: true},
+			Amount:    amount,
+			Currency:  payment.Currency,
+		}
+	}
+	organizer := uuid.NullUUID{UUID: ticket.Event.UserId, Valid: true}
+
+	var sale entities.ResaleSale
+	err := tx.Where("payment_id = ?", payment.ID).First(&sale).Error
+	switch {
+	case err == nil:
+		return post(tx, entities.LedgerSale, []entities.LedgerEntry{
+			leg(entities.AccountCash, uuid.NullUUID{}, payment.PaidAmount),
+			leg(entities.AccountPayable, uuid.NullUUID{UUID: sale.SellerId, Valid: true}, -sale.Proceeds),
+			leg(entities.AccountRevenue, uuid.NullUUID{}, -sale.Fee),
+			leg(entities.AccountPayable, organizer, -(payment.PaidAmount - sale.Amount)),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return post(tx, entities.LedgerSale, []entities.LedgerEntry{
+			leg(entities.AccountCash, uuid.NullUUID{}, payment.PaidAmount),
+			leg(entities.AccountRevenue, uuid.NullUUID{}, -payment.FeeAmount),
+			leg(entities.AccountPayable, organizer, -(payment.PaidAmount - payment.FeeAmount)),
+		})
+	default:
+		return err
+	}
+}
+
+// RecordRefund reverses the share of payment's sale that refund hands back,
+// taking it from each party in proportion to what they were credited.
+func RecordRefund(tx *gorm.DB, payment *entities.Payment, refund *entities.Refund) error {
+	if payment.PaidAmount == 0 || refund.Amount == 0 {
+		return nil
+	}
+	// a payment split off for a transfer is refunded out of the sale of the
+	// payment it came from, in proportion to what that one was paid
+	source := payment
+	if payment.TransferredFrom.Valid {
+		source = &entities.Payment{}
+		if err := tx.Where("id = ?", payment.TransferredFrom.UUID).First(source).Error; err != nil {
+			return err
+		}
+	}
+
+	var sale []entities.LedgerEntry
+	err := tx.Where("payment_id = ? AND kind = ? AND account <> ?", source.ID, entities.LedgerSale, entities.AccountCash).
+		Order("amount ASC").
+		Find(&sale).Error
+	if err != nil {
+		return err
+	}
+	if len(sale) == 0 {
+		return nil
+	}
+	return post(tx, entities.LedgerRefund, refundLegs(sale, refund, source.PaidAmount, payment.Currency))
+}
+
+// refundLegs are the legs reversing refund out of the credits of a sale that
+// collected paid, ordered by amount so the largest credit comes first.
+func refundLegs(sale []entities.LedgerEntry, refund *entities.Refund, paid int64, currency string) []entities.LedgerEntry {
+	refundId := uuid.NullUUID{UUID: refund.ID, Valid: true}
+	legs := []entities.LedgerEntry{{
+		Account:   entities.AccountCash,
+		EventId:   sale[0].EventId,
+		PaymentId: sale[0].PaymentId,
+		RefundId:  refundId,
+		Amount:    -refund.Amount,
+		Currency:  currency,
+	}}
+	var reversed int64
+	for _, entry := range sale {
+		amount := -entry.Amount * refund.Amount / paid
+		reversed += amount
+		legs = append(legs, entities.LedgerEntry{
+			Account:   entry.Account,
+			UserId:    entry.UserId,
+			EventId:   entry.EventId,
+			PaymentId: entry.PaymentId,
+			RefundId:  refundId,
+			Amount:    amount,
+			Currency:  entry.Currency,
+		})
+	}
+	// rounding is settled by the largest credit, ordered first
+	legs[1].Amount += refund.Amount - reversed
+	return legs
+}
@@ -0,0 +1,139 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+func TestPostRejectsUnbalancedLegs(t *testing.T) {
+	tests := []struct {
+		name string
+		legs []entities.LedgerEntry
+		want error
+	}{
+		{
+			name: "no legs",
+			want: nil,
+		},
+		{
+			name: "only empty legs",
+			legs: []entities.LedgerEntry{{Account: entities.AccountCash}, {Account: entities.AccountRevenue}},
+			want: nil,
+		},
+		{
+			name: "credits short of the cash",
+			legs: []entities.LedgerEntry{
+				{Account: entities.AccountCash, Amount: 1000},
+				{Account: entities.AccountRevenue, Amount: -100},
+				{Account: entities.AccountPayable, Amount: -899},
+			},
+			want: ErrUnbalanced,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// neither case reaches the database
+			if err := post(nil, entities.LedgerSale, tt.legs); !errors.Is(err, tt.want) {
+				t.Errorf("post() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundLegs(t *testing.T) {
+	paymentId := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	credit := func(account string, amount int64) entities.LedgerEntry {
+		return entities.LedgerEntry{Account: account, PaymentId: paymentId, Amount: amount, Currency: "USD"}
+	}
+	// a sale of 10000 with a 350 platform fee, largest credit first
+	sale := []entities.LedgerEntry{
+		credit(entities.AccountPayable, -9650),
+		credit(entities.AccountRevenue, -350),
+	}
+	// a resale of 11000: 9500 to the seller, 1000 tax to the organizer and a
+	// 500 resale fee
+	resale := []entities.LedgerEntry{
+		credit(entities.AccountPayable, -9500),
+		credit(entities.AccountPayable, -1000),
+		credit(entities.AccountRevenue, -500),
+	}
+
+	tests := []struct {
+		name   string
+		sale   []entities.LedgerEntry
+		paid   int64
+		refund int64
+		want   []int64
+	}{
+		{
+			name:   "full refund reverses every credit",
+			sale:   sale,
+			paid:   10000,
+			refund: 10000,
+			want:   []int64{-10000, 9650, 350},
+		},
+		{
+			name:   "partial refund splits exactly",
+			sale:   sale,
+			paid:   10000,
+			refund: 2000,
+			want:   []int64{-2000, 1930, 70},
+		},
+		{
+			name:   "rounding goes to the largest credit",
+			sale:   sale,
+			paid:   10000,
+			refund: 3333,
+			want:   []int64{-3333, 3217, 116},
+		},
+		{
+			name:   "single minor unit",
+			sale:   sale,
+			paid:   10000,
+			refund: 1,
+			want:   []int64{-1, 1, 0},
+		},
+		{
+			name:   "resale refund reaches seller, organizer and platform",
+			sale:   resale,
+			paid:   11000,
+			refund: 5500,
+			want:   []int64{-5500, 4750, 500, 250},
+		},
+		{
+			name:   "resale rounding goes to the seller",
+			sale:   resale,
+			paid:   11000,
+			refund: 1001,
+			want:   []int64{-1001, 865, 91, 45},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := &entities.Refund{ID: uuid.New(), Amount: tt.refund}
+			legs := refundLegs(tt.sale, refund, tt.paid, "USD")
+			if len(legs) != len(tt.want) {
+				t.Fatalf("refundLegs() returned %d legs, want %d", len(legs), len(tt.want))
+			}
+			if legs[0].Account != entities.AccountCash {
+				t.Errorf("first leg account = %q, want %q", legs[0].Account, entities.AccountCash)
+			}
+			var sum int64
+			for i, leg := range legs {
+				if leg.Amount != tt.want[i] {
+					t.Errorf("leg %d amount = %d, want %d", i, leg.Amount, tt.want[i])
+				}
+				if leg.RefundId.UUID != refund.ID {
+					t.Errorf("leg %d refund id = %v, want %v", i, leg.RefundId.UUID, refund.ID)
+				}
+				sum += leg.Amount
+			}
+			if sum != 0 {
+				t.Errorf("legs sum to %d, want 0", sum)
+			}
+		})
+	}
+}
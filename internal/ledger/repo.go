@@ -0,0 +1,45 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+// getUserPayouts pages through a user's payouts, newest first.
+func (service *LedgerService) getUserPayouts(ctx context.Context, userId uuid.UUID, p *utils.CursorPagination) ([]entities.Payout, utils.Page, error) {
+	var payouts []entities.Payout
+	keyset := utils.Keyset{Name: "created_at", Table: "payouts", Column: "payouts.created_at", Desc: true}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Where("user_id = ?", userId), p, keyset, func(p *entities.Payout) uuid.UUID { return p.ID }, &payouts)
+	if err != nil {
+		return nil, page, err
+	}
+	return payouts, page, nil
+}
+
+// getUnsettled sums, per currency, what the user is owed but has not been
+// paid out yet.
+func (service *LedgerService) getUnsettled(ctx context.Context, userId uuid.UUID) ([]Balance, error) {
+	var balances []Balance
+	err := service.db.WithContext(ctx).Model(&entities.LedgerEntry{}).
+		Select("currency, -SUM(amount) AS amount").
+		Where("account = ? AND user_id = ? AND payout_id IS NULL", entities.AccountPayable, userId).
+		Group("currency").
+		Order("currency").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// getPayableEntries lists the entries of the user's payable account posted
+// in [from, to), oldest first.
+func (service *LedgerService) getPayableEntries(ctx context.Context, userId uuid.UUID, from, to time.Time) ([]entities.LedgerEntry, error) {
+	var entries []entities.LedgerEntry
+	err := service.db.WithContext(ctx).
+		Where("account = ? AND user_id = ? AND created_at >= ? AND created_at < ?", entities.AccountPayable, userId, from, to).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
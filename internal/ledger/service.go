@@ -0,0 +1,52 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+// defaultSettlementHold keeps recent sales out of payouts while refunds and
+// chargebacks are still likely.
+const defaultSettlementHold = 72 * time.Hour
+
+var ErrUnbalanced = errors.New("ledger transaction does not balance")
+
+type LedgerService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+	hold   time.Duration
+}
+
+func NewLedgerService(db *gorm.DB, logger *slog.Logger) *LedgerService {
+	hold := defaultSettlementHold
+	if value := os.Getenv("PAYOUT_HOLD"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window < 0 {
+			logger.Warn("invalid PAYOUT_HOLD, using default", "value", value, "default", defaultSettlementHold.String())
+		} else {
+			hold = window
+		}
+	}
+	return &LedgerService{db: db, logger: logger, hold: hold}
+}
+
+// RunSettlement batches what users are owed into payouts every interval,
+// until ctx is canceled.
+func (service *LedgerService) RunSettlement(ctx context.Context, interval time.Duration) {
+	utils.Every(ctx, interval, func(ctx context.Context) {
+		payouts, err := service.settle(ctx, time.Now().Add(-service.hold))
+		if err != nil {
+			service.logger.Error("failed settling payouts", "error", err.Error())
+			return
+		}
+		if payouts > 0 {
+			service.logger.Info("created payouts", "count", payouts)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type balanceKey struct {
+	UserId   uuid.UUID
+	Currency string
+}
+
+// settle creates a payout for every user and currency with a positive
+// balance of unsettled payable entries posted before cutoff. Negative
+// balances, left by refunds of settled sales, carry over to later payouts.
+func (service *LedgerService) settle(ctx context.Context, cutoff time.Time) (int, error) {
+	var created int
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entries []entities.LedgerEntry
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("account = ? AND payout_id IS NULL AND user_id IS NOT NULL AND created_at < ?", entities.AccountPayable, cutoff).
+			Find(&entries).Error
+		if err != nil {
+			return err
+		}
+
+		balances := map[balanceKey][]uuid.UUID{}
+		owed := map[balanceKey]int64{}
+		for _, entry := range entries {
+			key := balanceKey{UserId: entry.UserId.UUID, Currency: entry.Currency}
+			balances[key] = append(balances[key], entry.ID)
+			owed[key] -= entry.Amount
+		}
+
+		for key, ids := range balances {
+			if owed[key] <= 0 {
+				continue
+			}
+			payout := entities.Payout{
+				ID:        uuid.New(),
+				UserId:    key.UserId,
+				Currency:  key.Currency,
+				Amount:    owed[key],
+				Entries:   len(ids),
+				PeriodEnd: cutoff,
+			}
+			if err := tx.Create(&payout).Error; err != nil {
+				return err
+			}
+			payoutId := uuid.NullUUID{UUID: payout.ID, Valid: true}
+			if err := tx.Model(&entities.LedgerEntry{}).Where("id IN ?", ids).Update("payout_id", payoutId).Error; err != nil {
+				return err
+			}
+			err := post(tx, entities.LedgerPayout, []entities.LedgerEntry{
+				{Account: entities.AccountPayable, UserId: uuid.NullUUID{UUID: key.UserId, Valid: true}, PayoutId: payoutId, Amount: payout.Amount, Currency: key.Currency},
+				{Account: entities.AccountCash, PayoutId: payoutId, Amount: -payout.Amount, Currency: key.Currency},
+			})
+			if err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	return created, err
+}
@@ -0,0 +1,82 @@
+// Package limits enforces how many units of a ticket, and of an event, a
+// single user may hold.
+package limits
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+
+// Check reports ErrPurchaseLimitExceeded when buying quantity
+// more units of ticket would take userId past the ticket's MaxPerUser or the
+// event's MaxTicketsPerUser. Units held by pending orders and by confirmed,
+// not refunded payments count towards the limits; excludeOrderId leaves out
+// the order being paid for. The buyer's user row is locked so concurrent
+// purchases by the same user are counted one after the other.
+func Check(tx *gorm.DB, userId uuid.UUID, ticket *entities.Ticket, quantity int, excludeOrderId uuid.UUID) error {
+	var event entities.Event
+	if err := tx.Select("id", "max_tickets_per_user").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+		return err
+	}
+	if ticket.MaxPerUser == 0 && event.MaxTicketsPerUser == 0 {
+		return nil
+	}
+
+	var user entities.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userId).First(&user).Error; err != nil {
+		return err
+	}
+
+	if ticket.MaxPerUser > 0 {
+		held, err := heldQuantity(tx, userId, excludeOrderId, "ticket_id = ?", ticket.ID)
+		if err != nil {
+			return err
+		}
+		if held+quantity > ticket.MaxPerUser {
+			return ErrPurchaseLimitExceeded
+		}
+	}
+
+	if event.MaxTicketsPerUser > 0 {
+		held, err := heldQuantity(tx, userId, excludeOrderId, "ticket_id IN (?)", tx.Model(&entities.Ticket{}).Select("id").Where("event_id = ?", event.ID))
+		if err != nil {
+			return err
+		}
+		if held+quantity > event.MaxTicketsPerUser {
+			return ErrPurchaseLimitExceeded
+		}
+	}
+	return nil
+}
+
+// heldQuantity sums the units userId holds through pending orders and
+// confirmed payments, less what was refunded or resold, on the tickets
+// matched by the ticket condition.
+func heldQuantity(tx *gorm.DB, userId, excludeOrderId uuid.UUID, ticketCondition string, args ...any) (int, error) {
+	var reserved int
+	err := tx.Model(&entities.Order{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("user_id = ? AND status = ? AND id <> ?", userId, entities.OrderPending, excludeOrderId).
+		Where(ticketCondition, args...).
+		Scan(&reserved).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var purchased int
+	err = tx.Model(&entities.Payment{}).
+		Select("COALESCE(SUM(quantity - refunded_quantity - resold_quantity - transferred_quantity), 0)").
+		Where("user_id = ? AND status IN ?", userId, []string{entities.PaymentConfirmed, entities.PaymentPartiallyRefunded}).
+		Where(ticketCondition, args...).
+		Scan(&purchased).Error
+	if err != nil {
+		return 0, err
+	}
+	return reserved + purchased, nil
+}
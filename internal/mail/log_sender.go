@@ -0,0 +1,21 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSender writes messages to the log instead of sending them, for
+// development. Messages may carry secrets, so it has no place in production.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, message Message) error {
+	s.logger.Info("email", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
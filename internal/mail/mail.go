@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages to their recipient.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// SenderFromEnv returns an SMTPSender when SMTP_ADDR is set, and a LogSender
+// for development otherwise.
+func SenderFromEnv(logger *slog.Logger) Sender {
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		return NewSMTPSender(addr, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	}
+	logger.Warn("SMTP_ADDR is not set, emails are logged instead of sent")
+	return NewLogSender(logger)
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPSender delivers messages through an SMTP relay, authenticating with
+// PLAIN auth when a username is set.
+type SMTPSender struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPSender(addr, from, username, password string) *SMTPSender {
+	return &SMTPSender{addr: addr, from: from, username: username, password: password}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	var body strings.Builder
+	body.WriteString("From: " + s.from + "\r\n")
+	body.WriteString("To: " + message.To + "\r\n")
+	body.WriteString("Subject: " + message.Subject + "\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return smtp.SendMail(s.addr, auth, s.from, []string{message.To}, []byte(body.String()))
+}
@@ -0,0 +1,60 @@
+package orders
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+type OrderCreateDTO struct {
+	TicketId uuid.UUID `json:"ticket_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required"`
+	// OnBehalfOf lets an admin place the order for another user
+	OnBehalfOf *uuid.UUID `json:"on_behalf_of"`
+	// AccessCode unlocks a hidden ticket
+	AccessCode *string `json:"access_code"`
+}
+
+func (o *OrderCreateDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	validator.Must(o.Quantity > 0, "quantity", "Quantity must be greater than 0")
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+type Order struct {
+	ID        uuid.UUID     `json:"id"`
+	UserId    uuid.UUID     `json:"user_id"`
+	ActedBy   uuid.NullUUID `json:"acted_by"`
+	TicketId  uuid.UUID     `json:"ticket_id"`
+	ListingId *uuid.UUID    `json:"listing_id,omitempty"`
+	Quantity  int           `json:"quantity"`
+	Amount    int64         `json:"amount"`
+	Currency  string        `json:"currency"`
+	Status    string        `json:"status"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func OrderEntityToOrder(o *entities.Order) Order {
+	order := Order{
+		ID:        o.ID,
+		UserId:    o.UserId,
+		ActedBy:   o.ActedBy,
+		TicketId:  o.TicketId,
+		Quantity:  o.Quantity,
+		Amount:    o.Amount,
+		Currency:  o.Currency,
+		Status:    o.Status,
+		ExpiresAt: o.ExpiresAt,
+		CreatedAt: o.CreatedAt,
+	}
+	if o.ListingId.Valid {
+		order.ListingId = &o.ListingId.UUID
+	}
+	return order
+}
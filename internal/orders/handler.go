@@ -0,0 +1,105 @@
+package orders
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/accesscodes"
+	"github.com/rezbow/tickr/internal/limits"
+	"gorm.io/gorm"
+)
+
+func (service *OrdersService) CreateOrderHandler(c *gin.Context) {
+	var input OrderCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	order, err := service.createOrder(c.Request.Context(), actor{UserId: userId, Role: c.GetString("user_role")}, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		case errors.Is(err, accesscodes.ErrAccessCodeRequired), errors.Is(err, accesscodes.ErrInvalidAccessCode):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrForeignKeyViolated):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user or ticket"})
+		case errors.Is(err, ErrInsufficientQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    "insufficient quantity",
+				"waitlist": "/tickets/" + input.TicketId.String() + "/waitlist",
+			})
+		case errors.Is(err, ErrNotOnSale):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ticket is not on sale"})
+		case errors.Is(err, ErrInvalidOrderQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quantity outside the ticket's per-order limits"})
+		case errors.Is(err, limits.ErrPurchaseLimitExceeded):
+			c.JSON(http.StatusConflict, gin.H{"error": "purchase limit exceeded"})
+		case errors.Is(err, ErrOnBehalfForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can order on behalf of other users"})
+		default:
+			service.logger.Error("failed creating order", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, OrderEntityToOrder(order))
+}
+
+func (service *OrdersService) GetOrderHandler(c *gin.Context) {
+	orderId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	order, err := service.getOrder(c.Request.Context(), orderId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		service.logger.Error("failed getting order", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, OrderEntityToOrder(order))
+}
+
+func (service *OrdersService) CancelOrderHandler(c *gin.Context) {
+	orderId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	order, err := service.cancelOrder(c.Request.Context(), orderId)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		case errors.Is(err, ErrOrderNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "order is not pending"})
+		default:
+			service.logger.Error("failed canceling order", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, OrderEntityToOrder(order))
+}
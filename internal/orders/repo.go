@@ -0,0 +1,188 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/accesscodes"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/limits"
+	"github.com/rezbow/tickr/internal/resale"
+	"github.com/rezbow/tickr/internal/waitlist"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sweepBatchSize bounds how many expired orders a single sweep releases.
+const sweepBatchSize = 100
+
+// createOrder holds input.Quantity units of a ticket for the caller, or for
+// input.OnBehalfOf when an admin orders for someone else. Hidden tickets need
+// input.AccessCode, unless an admin is placing the order.
+func (service *OrdersService) createOrder(ctx context.Context, actor actor, input OrderCreateDTO) (*entities.Order, error) {
+	userId := actor.UserId
+	var actedBy uuid.NullUUID
+	if input.OnBehalfOf != nil {
+		if actor.Role != "admin" {
+			return nil, ErrOnBehalfForbidden
+		}
+		userId = *input.OnBehalfOf
+		actedBy = uuid.NullUUID{UUID: actor.UserId, Valid: true}
+	}
+
+	var order entities.Order
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ticket entities.Ticket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", input.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+
+		var accessCodeId uuid.NullUUID
+		if !actedBy.Valid {
+			var err error
+			if accessCodeId, err = accesscodes.Unlock(tx, &ticket, input.AccessCode); err != nil {
+				return err
+			}
+		}
+
+		if !ticket.OnSale(time.Now()) {
+			return ErrNotOnSale
+		}
+		var event entities.Event
+		if err := tx.Select("id", "status", "capacity").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+			return err
+		}
+		if !event.OnSale() {
+			return ErrNotOnSale
+		}
+		if input.Quantity < ticket.MinPerOrder || (ticket.MaxPerOrder > 0 && input.Quantity > ticket.MaxPerOrder) {
+			return ErrInvalidOrderQuantity
+		}
+		if ticket.RemainingQuantities < input.Quantity {
+			return ErrInsufficientQuantity
+		}
+		if err := limits.Check(tx, userId, &ticket, input.Quantity, uuid.Nil); err != nil {
+			return err
+		}
+		if event.Capacity > 0 {
+			// serializes orders across the event's tickets while counting
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", event.ID).First(&entities.Event{}).Error; err != nil {
+				return err
+			}
+			var taken int
+			err := tx.Model(&entities.Ticket{}).
+				Select("COALESCE(SUM(total_quantities - remaining_quantities), 0)").
+				Where("event_id = ?", event.ID).
+				Scan(&taken).Error
+			if err != nil {
+				return err
+			}
+			if taken+input.Quantity > event.Capacity {
+				return ErrInsufficientQuantity
+			}
+		}
+
+		ticket.RemainingQuantities -= input.Quantity
+		ticket.ReservedQuantities += input.Quantity
+		if err := tx.Save(&ticket).Error; err != nil {
+			return err
+		}
+
+		order = entities.Order{
+			ID:           uuid.New(),
+			UserId:       userId,
+			ActedBy:      actedBy,
+			TicketId:     ticket.ID,
+			AccessCodeId: accessCodeId,
+			Quantity:     input.Quantity,
+			Amount:       int64(input.Quantity) * ticket.Price,
+			Currency:     ticket.Currency,
+			Status:       entities.OrderPending,
+			ExpiresAt:    time.Now().Add(service.holdWindow),
+		}
+		return tx.Create(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (service *OrdersService) getOrder(ctx context.Context, orderId uuid.UUID) (*entities.Order, error) {
+	order, err := gorm.G[entities.Order](service.db).Where("id = ?", orderId).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (service *OrdersService) cancelOrder(ctx context.Context, orderId uuid.UUID) (*entities.Order, error) {
+	var order entities.Order
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderId).First(&order).Error; err != nil {
+			return err
+		}
+		if order.Status != entities.OrderPending {
+			return ErrOrderNotPending
+		}
+		return ReleaseOrder(tx, &order, entities.OrderCanceled)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// releaseExpiredOrders moves pending orders past their hold window to expired
+// and hands their reserved quantities back to the ticket.
+func (service *OrdersService) releaseExpiredOrders(ctx context.Context) (int, error) {
+	var released int
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var orders []entities.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at < ?", entities.OrderPending, time.Now()).
+			Order("expires_at ASC").
+			Limit(sweepBatchSize).
+			Find(&orders).Error
+		if err != nil {
+			return err
+		}
+		for i := range orders {
+			if err := ReleaseOrder(tx, &orders[i], entities.OrderExpired); err != nil {
+				return err
+			}
+		}
+		released = len(orders)
+		return nil
+	})
+	return released, err
+}
+
+// ReleaseOrder returns the order's held quantity to the ticket, offering it to
+// the ticket's waitlist, or to the resale listing it was ordered from, and
+// moves the order to status. The order row must already be locked by tx.
+func ReleaseOrder(tx *gorm.DB, order *entities.Order, status string) error {
+	order.Status = status
+	if err := tx.Model(order).Update("status", status).Error; err != nil {
+		return err
+	}
+	if order.ListingId.Valid {
+		return resale.ReleaseHold(tx, order)
+	}
+	if err := waitlist.CloseOffer(tx, order.ID, status); err != nil {
+		return err
+	}
+
+	var ticket entities.Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.TicketId).First(&ticket).Error; err != nil {
+		return err
+	}
+
+	ticket.ReservedQuantities -= order.Quantity
+	ticket.RemainingQuantities += order.Quantity
+	if err := waitlist.OfferReleased(tx, &ticket); err != nil {
+		return err
+	}
+	return tx.Save(&ticket).Error
+}
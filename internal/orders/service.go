@@ -0,0 +1,68 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+const defaultHoldWindow = 15 * time.Minute
+
+var (
+	ErrInsufficientQuantity = errors.New("insufficient quantities")
+	ErrOrderNotPending      = errors.New("order is not pending")
+	ErrOnBehalfForbidden    = errors.New("only admins can order on behalf of other users")
+	ErrNotOnSale            = errors.New("ticket is not on sale")
+	ErrInvalidOrderQuantity = errors.New("quantity outside the ticket's per-order limits")
+)
+
+// actor is the authenticated user making a request.
+type actor struct {
+	UserId uuid.UUID
+	Role   string
+}
+
+type OrdersService struct {
+	db         *gorm.DB
+	logger     *slog.Logger
+	holdWindow time.Duration
+}
+
+func NewOrdersService(db *gorm.DB, logger *slog.Logger) *OrdersService {
+	holdWindow := defaultHoldWindow
+	if value := os.Getenv("ORDER_HOLD_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			logger.Warn("invalid ORDER_HOLD_WINDOW, using default", "value", value, "default", defaultHoldWindow.String())
+		} else {
+			holdWindow = window
+		}
+	}
+	return &OrdersService{db: db, logger: logger, holdWindow: holdWindow}
+}
+
+// HoldWindow is how long pending orders hold their units.
+func (service *OrdersService) HoldWindow() time.Duration {
+	return service.holdWindow
+}
+
+// RunExpirySweeper releases the holds of pending orders whose hold window has
+// elapsed, every interval, until ctx is canceled.
+func (service *OrdersService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	utils.Every(ctx, interval, func(ctx context.Context) {
+		released, err := service.releaseExpiredOrders(ctx)
+		if err != nil {
+			service.logger.Error("failed releasing expired orders", "error", err.Error())
+			return
+		}
+		if released > 0 {
+			service.logger.Info("released expired orders", "count", released)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package passes
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// qrPrefix versions the QR payload format.
+const qrPrefix = "TICKR1"
+
+var ErrInvalidQRPayload = errors.New("invalid QR payload")
+
+var codeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newCode returns an unguessable 160-bit pass code.
+func newCode() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return codeEncoding.EncodeToString(buf), nil
+}
+
+// QRPayload encodes a pass as "TICKR1.<pass id>.<code>.<signature>" where the
+// signature is an HMAC of the id and code, so scanners can reject forged
+// payloads before looking the code up.
+func QRPayload(secret []byte, passId uuid.UUID, code string) string {
+	body := qrPrefix + "." + passId.String() + "." + code
+	return body + "." + sign(secret, body)
+}
+
+// ParseQRPayload verifies a payload produced by QRPayload and returns the
+// pass id and code it carries.
+func ParseQRPayload(secret []byte, payload string) (uuid.UUID, string, error) {
+	parts := strings.Split(payload, ".")
+	if len(parts) != 4 || parts[0] != qrPrefix {
+		return uuid.Nil, "", ErrInvalidQRPayload
+	}
+	body := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(sign(secret, body)), []byte(parts[3])) {
+		return uuid.Nil, "", ErrInvalidQRPayload
+	}
+	passId, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidQRPayload
+	}
+	return passId, parts[2], nil
+}
+
+func sign(secret []byte, body string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,47 @@
+package passes
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+type Pass struct {
+	ID          uuid.UUID  `json:"id"`
+	PaymentId   uuid.UUID  `json:"payment_id"`
+	TicketId    uuid.UUID  `json:"ticket_id"`
+	EventId     uuid.UUID  `json:"event_id"`
+	UserId      uuid.UUID  `json:"user_id"`
+	Code        string     `json:"code"`
+	QRPayload   string     `json:"qr_payload"`
+	QRImageURL  string     `json:"qr_image_url"`
+	Status      string     `json:"status"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+func PassEntityToPass(secret []byte, p *entities.Pass) Pass {
+	pass := Pass{
+		ID:         p.ID,
+		PaymentId:  p.PaymentId,
+		TicketId:   p.TicketId,
+		EventId:    p.EventId,
+		UserId:     p.UserId,
+		Code:       p.Code,
+		QRPayload:  QRPayload(secret, p.ID, p.Code),
+		QRImageURL: "/passes/" + p.ID.String() + "/qr.png",
+		Status:     p.Status,
+	}
+	if p.CheckedInAt.Valid {
+		pass.CheckedInAt = &p.CheckedInAt.Time
+	}
+	return pass
+}
+
+func PassEntitiesToPasses(secret []byte, passes []entities.Pass) []Pass {
+	result := make([]Pass, len(passes))
+	for i := range passes {
+		result[i] = PassEntityToPass(secret, &passes[i])
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package passes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/utils"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+func (service *PassesService) GetMyPassesHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	passes, page, err := service.getUserPasses(c.Request.Context(), userId, &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get passes", "userId", userId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        PassEntitiesToPasses(service.secret, passes),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
+
+func (service *PassesService) GetPaymentPassesHandler(c *gin.Context) {
+	paymentId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+
+	passes, err := service.getPaymentPasses(c.Request.Context(), paymentId)
+	if err != nil {
+		service.logger.Error("failed to get payment passes", "paymentId", paymentId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": PassEntitiesToPasses(service.secret, passes)})
+}
+
+func (service *PassesService) GetPassQRHandler(c *gin.Context) {
+	passId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pass not found"})
+		return
+	}
+
+	pass, err := service.getPass(c.Request.Context(), passId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "pass not found"})
+			return
+		}
+		service.logger.Error("failed to get pass", "passId", passId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	png, err := qrcode.Encode(QRPayload(service.secret, pass.ID, pass.Code), qrcode.Medium, qrSize)
+	if err != nil {
+		service.logger.Error("failed to render QR code", "passId", passId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
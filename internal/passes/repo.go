@@ -0,0 +1,109 @@
+package passes
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IssuePasses creates one pass per unit of a confirmed payment, held by the
+// buyer.
+func IssuePasses(tx *gorm.DB, payment *entities.Payment, eventId uuid.UUID) error {
+	passes := make([]entities.Pass, payment.Quantity)
+	for i := range passes {
+		code, err := newCode()
+		if err != nil {
+			return err
+		}
+		passes[i] = entities.Pass{
+			ID:        uuid.New(),
+			PaymentId: payment.ID,
+			TicketId:  payment.TicketId,
+			EventId:   eventId,
+			UserId:    payment.UserId,
+			Code:      code,
+			Status:    entities.PassValid,
+		}
+	}
+	return tx.Create(&passes).Error
+}
+
+// VoidPasses voids quantity passes of a payment, unused ones first.
+func VoidPasses(tx *gorm.DB, paymentId uuid.UUID, quantity int) error {
+	var ids []uuid.UUID
+	err := tx.Model(&entities.Pass{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_id = ? AND status <> ?", paymentId, entities.PassVoid).
+		Order(clause.Expr{SQL: "status = ? DESC, created_at DESC", Vars: []any{entities.PassValid}}).
+		Limit(quantity).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	return tx.Model(&entities.Pass{}).Where("id IN ?", ids).Update("status", entities.PassVoid).Error
+}
+
+func (service *PassesService) getPass(ctx context.Context, passId uuid.UUID) (*entities.Pass, error) {
+	pass, err := gorm.G[entities.Pass](service.db).Where("id = ?", passId).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pass, nil
+}
+
+func (service *PassesService) getPaymentPasses(ctx context.Context, paymentId uuid.UUID) ([]entities.Pass, error) {
+	return gorm.G[entities.Pass](service.db).Where("payment_id = ?", paymentId).Order("created_at ASC").Find(ctx)
+}
+
+// getUserPasses pages through the passes a user holds, newest first.
+func (service *PassesService) getUserPasses(ctx context.Context, userId uuid.UUID, p *utils.CursorPagination) ([]entities.Pass, utils.Page, error) {
+	var passes []entities.Pass
+	keyset := utils.Keyset{Name: "created_at", Table: "passes", Column: "passes.created_at", Desc: true}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Where("user_id = ?", userId), p, keyset, func(p *entities.Pass) uuid.UUID { return p.ID }, &passes)
+	if err != nil {
+		return nil, page, err
+	}
+	return passes, page, nil
+}
+
+// MovePasses hands the passes ids to userId and their payment paymentId with
+// fresh codes, so codes the previous holder kept no longer admit anyone and
+// refunding the previous holder leaves them alone.
+func MovePasses(tx *gorm.DB, ids []uuid.UUID, userId, paymentId uuid.UUID) error {
+	return reissue(tx, ids, map[string]any{"user_id": userId, "payment_id": paymentId})
+}
+
+func reissue(tx *gorm.DB, ids []uuid.UUID, updates map[string]any) error {
+	for _, id := range ids {
+		code, err := newCode()
+		if err != nil {
+			return err
+		}
+		updates["code"] = code
+		if err := tx.Model(&entities.Pass{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unencumbered scopes a pass query to passes not set aside by a pending
+// transfer or an active resale listing.
+func Unencumbered(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where(`NOT EXISTS (
+				SELECT 1 FROM transfer_passes JOIN transfers ON transfers.id = transfer_passes.transfer_id
+				WHERE transfer_passes.pass_id = passes.id AND transfers.status = ? AND transfers.expires_at > ?)`,
+				entities.TransferPending, now).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM listing_passes JOIN listings ON listings.id = listing_passes.listing_id
+				WHERE listing_passes.pass_id = passes.id AND listings.status = ? AND NOT listing_passes.sold)`,
+				entities.ListingActive)
+	}
+}
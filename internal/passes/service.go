@@ -0,0 +1,39 @@
+package passes
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// qrSize is the edge length in pixels of rendered QR images.
+const qrSize = 256
+
+var ErrMissingSigningSecret = errors.New("PASS_SIGNING_SECRET must be set")
+
+type PassesService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+	secret []byte
+}
+
+func NewPassesService(db *gorm.DB, logger *slog.Logger) *PassesService {
+	secret, err := SigningSecret()
+	if err != nil {
+		panic(err.Error())
+	}
+	return &PassesService{db: db, logger: logger, secret: secret}
+}
+
+// SigningSecret returns the key QR payloads are signed with, from
+// PASS_SIGNING_SECRET. Anyone holding it can mint passes, so there is no
+// fallback secret.
+func SigningSecret() ([]byte, error) {
+	secret := os.Getenv("PASS_SIGNING_SECRET")
+	if secret == "" {
+		return nil, ErrMissingSigningSecret
+	}
+	return []byte(secret), nil
+}
@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/events"
+	"github.com/rezbow/tickr/internal/orders"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// canceledEvent is what canceling an event undid.
+type canceledEvent struct {
+	Event    *entities.Event
+	Orders   int
+	Payments []entities.Payment
+	Refunds  []entities.Refund
+}
+
+// cancelEvent cancels an event together with everything sold for it: pending
+// orders are released, and every confirmed payment is refunded in full,
+// moved to canceled and its units handed back to the ticket. The gateway is
+// refunded once the transaction commits, failures are logged for
+// reconciliation so they never undo the cancellation.
+func (svc *PaymentService) cancelEvent(ctx context.Context, eventId uuid.UUID, actor actor) (*canceledEvent, error) {
+	var canceled canceledEvent
+	err := svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		event, err := events.Transition(tx, eventId, entities.EventCanceled)
+		if err != nil {
+			return err
+		}
+		canceled = canceledEvent{Event: event}
+		tickets := tx.Model(&entities.Ticket{}).Select("id").Where("event_id = ?", eventId)
+
+		// the event is canceled now, so released units are not offered to waitlists
+		var pending []entities.Order
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("ticket_id IN (?) AND status = ?", tickets, entities.OrderPending).
+			Find(&pending).Error
+		if err != nil {
+			return err
+		}
+		for i := range pending {
+			if err := orders.ReleaseOrder(tx, &pending[i], entities.OrderCanceled); err != nil {
+				return err
+			}
+		}
+		canceled.Orders = len(pending)
+
+		var ids []uuid.UUID
+		err = tx.Model(&entities.Payment{}).
+			Where("ticket_id IN (?) AND status IN ?", tickets, []string{entities.PaymentConfirmed, entities.PaymentPartiallyRefunded}).
+			Order("created_at ASC").
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		issuer := uuid.NullUUID{UUID: actor.UserId, Valid: true}
+		for _, id := range ids {
+			payment, ticket, err := lockPaymentAndTicket(tx, id)
+			if err != nil {
+				return err
+			}
+			// units resold on are refunded through the buyer's payment
+			if quantity := payment.RefundableQuantity(); quantity > 0 {
+				refund, err := applyRefund(tx, payment, ticket, quantity, issuer, "event canceled")
+				if err != nil {
+					return err
+				}
+				canceled.Refunds = append(canceled.Refunds, *refund)
+			}
+			payment.Status = entities.PaymentCanceled
+			if err := tx.Model(payment).Update("status", entities.PaymentCanceled).Error; err != nil {
+				return err
+			}
+			canceled.Payments = append(canceled.Payments, *payment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	references := make(map[uuid.UUID]string, len(canceled.Payments))
+	for _, payment := range canceled.Payments {
+		references[payment.ID] = payment.ProviderRef
+	}
+	for i := range canceled.Refunds {
+		refund := &canceled.Refunds[i]
+		svc.sendRefund(ctx, references[refund.PaymentId], refund)
+	}
+	return &canceled, nil
+}
+
+func (svc *PaymentService) CancelEventHandler(c *gin.Context) {
+	eventId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	canceled, err := svc.cancelEvent(c.Request.Context(), eventId, actor{UserId: userId, Role: c.GetString("user_role")})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		case errors.Is(err, events.ErrInvalidTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			svc.logger.Error("failed canceling event", "eventId", eventId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":             events.EventEntityToEventResponse(canceled.Event),
+		"canceled_orders":   canceled.Orders,
+		"canceled_payments": len(canceled.Payments),
+		"refunds":           RefundEntitiesToRefunds(canceled.Refunds),
+	})
+}
@@ -1,35 +1,214 @@
 package payment
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/pricing"
 	"github.com/rezbow/tickr/internal/utils"
 )
 
 type PaymentDetail struct {
-	TicketId uuid.UUID `json:"ticket_id" binding:"required"`
-	UserId   uuid.UUID `json:"user_id" binding:"required"`
-	Quantity int       `json:"quantity" binding:"required"`
+	OrderId uuid.UUID `json:"order_id" binding:"required"`
+	// OnBehalfOf lets an admin pay for an order placed for another user
+	OnBehalfOf *uuid.UUID `json:"on_behalf_of"`
+	// DiscountCode is a promo code of the ticket's event
+	DiscountCode *string `json:"discount_code"`
 }
 
 type Payment struct {
-	ID       uuid.UUID `json:"id"`
-	UserId   uuid.UUID `json:"user_id"`
-	Ticket   uuid.UUID `json:"ticket_id"`
-    Quantity int       `json:"quantity"`
+	ID         uuid.UUID     `json:"id"`
+	UserId     uuid.UUID     `json:"user_id"`
+	ActedBy    uuid.NullUUID `json:"acted_by"`
+	Ticket     uuid.UUID     `json:"ticket_id"`
+	OrderId    uuid.UUID     `json:"order_id"`
+	Quantity   int           `json:"quantity"`
+	PaidAmount int64         `json:"paid_amount"`
+	Status     string        `json:"status"`
+	Currency   string        `json:"currency"`
+
+	FaceAmount     int64         `json:"face_amount"`
+	DiscountCodeId uuid.NullUUID `json:"discount_code_id"`
+	DiscountAmount int64         `json:"discount_amount"`
+	FeeAmount      int64         `json:"fee_amount"`
+	FeeAbsorbed    bool          `json:"fee_absorbed"`
+	TaxAmount      int64         `json:"tax_amount"`
+
+	RefundedQuantity int   `json:"refunded_quantity"`
+	RefundedAmount   int64 `json:"refunded_amount"`
+	ResoldQuantity   int   `json:"resold_quantity"`
+
+	TransferredQuantity int           `json:"transferred_quantity"`
+	TransferredFrom     uuid.NullUUID `json:"transferred_from"`
 }
 
 func PaymentEntityToPayment(p entities.Payment) Payment {
 	return Payment{
-		ID:       p.ID,
-		UserId:   p.UserId,
-		Ticket:   p.TicketId,
-		Quantity: p.Quantity,
+		ID:         p.ID,
+		UserId:     p.UserId,
+		ActedBy:    p.ActedBy,
+		Ticket:     p.TicketId,
+		OrderId:    p.OrderId,
+		Quantity:   p.Quantity,
+		PaidAmount: p.PaidAmount,
+		Status:     p.Status,
+		Currency:   p.Currency,
+
+		FaceAmount:     p.FaceAmount,
+		DiscountCodeId: p.DiscountCodeId,
+		DiscountAmount: p.DiscountAmount,
+		FeeAmount:      p.FeeAmount,
+		FeeAbsorbed:    p.FeeAbsorbed,
+		TaxAmount:      p.TaxAmount,
+
+		RefundedQuantity: p.RefundedQuantity,
+		RefundedAmount:   p.RefundedAmount,
+		ResoldQuantity:   p.ResoldQuantity,
+
+		TransferredQuantity: p.TransferredQuantity,
+		TransferredFrom:     p.TransferredFrom,
+	}
+}
+
+func PaymentEntitiesToPayments(payments []entities.Payment) []Payment {
+	result := make([]Payment, len(payments))
+	for i, p := range payments {
+		result[i] = PaymentEntityToPayment(p)
 	}
+	return result
 }
 
 func (pd *PaymentDetail) Validate() utils.ValidationErrors {
 	validator := utils.NewValidator()
-	validator.Must(pd.Quantity > 0, "quantity", "Quantity must be greater than 0")
-	return validator.Errors
+	validator.Must(pd.OrderId != uuid.Nil, "order_id", "order_id is required")
+	if pd.DiscountCode != nil {
+		validator.Must(strings.TrimSpace(*pd.DiscountCode) != "", "discount_code", "discount_code must not be empty")
+	}
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+// QuoteDTO asks for the price of an existing order, or of Quantity units of
+// TicketId before ordering them.
+type QuoteDTO struct {
+	OrderId      *uuid.UUID `json:"order_id"`
+	TicketId     *uuid.UUID `json:"ticket_id"`
+	Quantity     *int       `json:"quantity"`
+	DiscountCode *string    `json:"discount_code"`
+	// AccessCode unlocks a hidden TicketId
+	AccessCode *string `json:"access_code"`
+}
+
+func (q *QuoteDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	validator.Must((q.OrderId == nil) != (q.TicketId == nil), "order_id", "exactly one of order_id and ticket_id is required")
+	if q.TicketId != nil {
+		validator.Must(q.Quantity != nil && *q.Quantity > 0, "quantity", "Quantity must be greater than 0")
+	}
+	if q.DiscountCode != nil {
+		validator.Must(strings.TrimSpace(*q.DiscountCode) != "", "discount_code", "discount_code must not be empty")
+	}
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+var (
+	LineFaceValue = "face_value"
+	LineDiscount  = "discount"
+	LineFee       = "fee"
+	LineTax       = "tax"
+)
+
+type LineItem struct {
+	Kind   string `json:"kind"`
+	Amount int64  `json:"amount"` // negative for discounts
+}
+
+type Quote struct {
+	OrderId     *uuid.UUID `json:"order_id,omitempty"`
+	TicketId    uuid.UUID  `json:"ticket_id"`
+	Quantity    int        `json:"quantity"`
+	Currency    string     `json:"currency"`
+	LineItems   []LineItem `json:"line_items"`
+	FeeAbsorbed bool       `json:"fee_absorbed"`
+	Total       int64      `json:"total"`
+}
+
+func BreakdownToQuote(o *entities.Order, b pricing.Breakdown) Quote {
+	quote := Quote{
+		TicketId:    o.TicketId,
+		Quantity:    o.Quantity,
+		Currency:    o.Currency,
+		LineItems:   []LineItem{{Kind: LineFaceValue, Amount: b.FaceAmount}},
+		FeeAbsorbed: b.FeeAbsorbed,
+		Total:       b.Total,
+	}
+	if o.ID != uuid.Nil {
+		quote.OrderId = &o.ID
+	}
+	if b.DiscountAmount > 0 {
+		quote.LineItems = append(quote.LineItems, LineItem{Kind: LineDiscount, Amount: -b.DiscountAmount})
+	}
+	// an absorbed fee comes out of the organizer's share, not the total
+	if b.FeeAmount > 0 && !b.FeeAbsorbed {
+		quote.LineItems = append(quote.LineItems, LineItem{Kind: LineFee, Amount: b.FeeAmount})
+	}
+	if b.TaxAmount > 0 {
+		quote.LineItems = append(quote.LineItems, LineItem{Kind: LineTax, Amount: b.TaxAmount})
+	}
+	return quote
+}
+
+type RefundCreateDTO struct {
+	// Quantity defaults to every unit not refunded yet
+	Quantity *int   `json:"quantity"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+func (r *RefundCreateDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	if r.Quantity != nil {
+		validator.Must(*r.Quantity > 0, "quantity", "Quantity must be greater than 0")
+	}
+	validator.Must(len(r.Reason) >= 2 && len(r.Reason) <= 1024, "reason", "reason must be between 2 and 1024 characters")
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+type Refund struct {
+	ID        uuid.UUID     `json:"id"`
+	PaymentId uuid.UUID     `json:"payment_id"`
+	UserId    uuid.NullUUID `json:"user_id"`
+	Quantity  int           `json:"quantity"`
+	Amount    int64         `json:"amount"`
+	Reason    string        `json:"reason"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func RefundEntityToRefund(r entities.Refund) Refund {
+	return Refund{
+		ID:        r.ID,
+		PaymentId: r.PaymentId,
+		UserId:    r.UserId,
+		Quantity:  r.Quantity,
+		Amount:    r.Amount,
+		Reason:    r.Reason,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func RefundEntitiesToRefunds(refunds []entities.Refund) []Refund {
+	result := make([]Refund, len(refunds))
+	for i, r := range refunds {
+		result[i] = RefundEntityToRefund(r)
+	}
+	return result
 }
@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	fakeAuthorized = "authorized"
+	fakeCaptured   = "captured"
+	fakeVoided     = "voided"
+)
+
+type fakeCharge struct {
+	status   string
+	amount   int64
+	captured int64
+	refunded int64
+}
+
+// FakeGateway is an in-memory PaymentGateway for development and tests. It
+// approves every authorization unless DeclineAbove is set and exceeded.
+type FakeGateway struct {
+	DeclineAbove int64
+
+	mu      sync.Mutex
+	charges map[string]*fakeCharge
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{charges: make(map[string]*fakeCharge)}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	if req.Amount < 0 || (g.DeclineAbove > 0 && req.Amount > g.DeclineAbove) {
+		return "", ErrPaymentDeclined
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	reference := "fake_" + uuid.NewString()
+	g.charges[reference] = &fakeCharge{status: fakeAuthorized, amount: req.Amount}
+	return reference, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[reference]
+	if !ok {
+		return ErrUnknownReference
+	}
+	if charge.status != fakeAuthorized || amount > charge.amount {
+		return ErrInvalidOperation
+	}
+	charge.status = fakeCaptured
+	charge.captured = amount
+	return nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[reference]
+	if !ok {
+		return ErrUnknownReference
+	}
+	if charge.status != fakeAuthorized {
+		return ErrInvalidOperation
+	}
+	charge.status = fakeVoided
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount int64) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	charge, ok := g.charges[reference]
+	if !ok {
+		return "", ErrUnknownReference
+	}
+	if charge.status != fakeCaptured || amount <= 0 || charge.refunded+amount > charge.captured {
+		return "", ErrInvalidOperation
+	}
+	charge.refunded += amount
+	return "fake_refund_" + uuid.NewString(), nil
+}
@@ -0,0 +1,33 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPaymentDeclined  = errors.New("payment declined")
+	ErrUnknownReference = errors.New("unknown payment reference")
+	ErrInvalidOperation = errors.New("operation not allowed in current payment state")
+)
+
+// PaymentGateway is the contract a payment processor has to fulfil. Amounts
+// are in the smallest currency unit, like entities.Payment.PaidAmount, and
+// reference is the processor's identifier returned by Authorize. Refund
+// returns the processor's identifier of the refund itself.
+type PaymentGateway interface {
+	// Name identifies the provider; it is stored on every payment it handles.
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (reference string, err error)
+	Capture(ctx context.Context, reference string, amount int64) error
+	Void(ctx context.Context, reference string) error
+	Refund(ctx context.Context, reference string, amount int64) (refundReference string, err error)
+}
+
+type AuthorizeRequest struct {
+	PaymentId uuid.UUID
+	UserId    uuid.UUID
+	Amount    int64
+}
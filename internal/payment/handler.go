@@ -1,11 +1,19 @@
 package payment
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/discounts"
+	"github.com/rezbow/tickr/internal/limits"
+	"github.com/rezbow/tickr/internal/orders"
+	"github.com/rezbow/tickr/internal/resale"
+	"github.com/rezbow/tickr/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -30,6 +38,38 @@ func (service *PaymentService) GetPaymentHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, PaymentEntityToPayment(*payment))
 }
 
+func (service *PaymentService) GetMyPaymentsHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	payments, page, err := service.getUserPayments(c.Request.Context(), userId, &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get payments", "userId", userId.String(), "page_size", p.PageSize, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        PaymentEntitiesToPayments(payments),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
+
 func (service *PaymentService) BuyTicketHandler(c *gin.Context) {
 	var paymentDetail PaymentDetail
 	if err := c.ShouldBindJSON(&paymentDetail); err != nil {
@@ -41,15 +81,46 @@ func (service *PaymentService) BuyTicketHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
 		return
 	}
-	payment, err := service.createPayment(paymentDetail)
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	payment, err := service.createPayment(c.Request.Context(), actor{UserId: userId, Role: c.GetString("user_role")}, paymentDetail)
 	if err != nil {
 		switch err {
 		case gorm.ErrRecordNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 		case gorm.ErrForeignKeyViolated:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user or ticket "})
-		case ErrInsuffcientQuantity:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "insufficient quantity"})
+		case ErrOrderNotPending:
+			c.JSON(http.StatusConflict, gin.H{"error": "order is not pending"})
+		case ErrOrderExpired:
+			c.JSON(http.StatusGone, gin.H{"error": "order hold has expired"})
+		case ErrPaymentInProgress:
+			c.JSON(http.StatusConflict, gin.H{"error": "a payment for this order is already in progress"})
+		case ErrPaymentDeclined:
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "payment declined"})
+		case orders.ErrNotOnSale:
+			c.JSON(http.StatusConflict, gin.H{"error": "ticket is not on sale"})
+		case limits.ErrPurchaseLimitExceeded:
+			c.JSON(http.StatusConflict, gin.H{"error": "purchase limit exceeded"})
+		case discounts.ErrInvalidCode:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case discounts.ErrCodeNotValid, discounts.ErrCodeNotApplicable:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case discounts.ErrCodeExhausted, discounts.ErrCodeUserLimit:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case resale.ErrListingNotActive, resale.ErrListingUnavailable:
+			c.JSON(http.StatusConflict, gin.H{"error": "listing is no longer available"})
+		case ErrCurrencyMismatch:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case ErrPaymentForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "order belongs to another user"})
+		case ErrOnBehalfForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can pay on behalf of other users"})
 		default:
 			service.logger.Error("payment failed", "error", err.Error())
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -59,3 +130,136 @@ func (service *PaymentService) BuyTicketHandler(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, PaymentEntityToPayment(*payment))
 }
+
+func (service *PaymentService) QuotePaymentHandler(c *gin.Context) {
+	var input QuoteDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	order, breakdown, err := service.quotePayment(c.Request.Context(), actor{UserId: userId, Role: c.GetString("user_role")}, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "order or ticket not found"})
+		case errors.Is(err, ErrPaymentForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "order belongs to another user"})
+		case errors.Is(err, discounts.ErrInvalidCode):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, discounts.ErrCodeNotValid), errors.Is(err, discounts.ErrCodeNotApplicable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, discounts.ErrCodeExhausted), errors.Is(err, discounts.ErrCodeUserLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("quote failed", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, BreakdownToQuote(order, breakdown))
+}
+
+func (service *PaymentService) RefundPaymentHandler(c *gin.Context) {
+	paymentId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+
+	var input RefundCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	role := c.GetString("user_role")
+
+	refund, payment, err := service.refundPayment(c.Request.Context(), paymentId, actor{UserId: userId, Role: role}, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, ErrRefundForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "don't have permission"})
+		case errors.Is(err, ErrRefundPolicyViolation):
+			c.JSON(http.StatusForbidden, gin.H{"error": "refund not allowed by event refund policy"})
+		case errors.Is(err, ErrNotRefundable):
+			c.JSON(http.StatusConflict, gin.H{"error": "payment is not refundable"})
+		case errors.Is(err, ErrInvalidRefundQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund quantity"})
+		default:
+			service.logger.Error("refund failed", "paymentId", paymentId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"refund":  RefundEntityToRefund(*refund),
+		"payment": PaymentEntityToPayment(*payment),
+	})
+}
+
+func (service *PaymentService) PaymentWebhookHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	secret := WebhookSecret(provider)
+	if secret == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown payment provider"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed reading request body"})
+		return
+	}
+	if err := VerifyWebhookSignature(secret, c.GetHeader(WebhookSignatureHeader), body, time.Now()); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload.ID == "" || payload.Type == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id and type are required"})
+		return
+	}
+
+	status, err := service.handleWebhookEvent(c.Request.Context(), provider, payload, body)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+			return
+		}
+		service.logger.Error("webhook processing failed", "provider", provider, "eventId", payload.ID, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
@@ -0,0 +1,97 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rezbow/tickr/internal/accesscodes"
+	"github.com/rezbow/tickr/internal/discounts"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/pricing"
+	"gorm.io/gorm"
+)
+
+// priceOrder prices order on ticket under its event's fee mode and tax rate,
+// with discount taken off the face value.
+func (svc *PaymentService) priceOrder(tx *gorm.DB, order *entities.Order, ticket *entities.Ticket, discount int64) (pricing.Breakdown, error) {
+	var event entities.Event
+	if err := tx.Select("id", "fee_mode", "tax_rate").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+		return pricing.Breakdown{}, err
+	}
+	fees := svc.fees
+	// resale sellers pay the platform through the resale fee
+	if order.ListingId.Valid {
+		fees = pricing.Fees{}
+	}
+	return pricing.Price(fees, &event, order.Quantity, order.Amount, discount), nil
+}
+
+// quotePayment prices a purchase the way startPayment would, without holding
+// units or using the promo code. It prices an existing order of the actor's,
+// or q.Quantity units of q.TicketId.
+func (svc *PaymentService) quotePayment(ctx context.Context, actor actor, q QuoteDTO) (*entities.Order, pricing.Breakdown, error) {
+	var order entities.Order
+	var breakdown pricing.Breakdown
+	err := svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if q.OrderId != nil {
+			if err := tx.Where("id = ?", *q.OrderId).First(&order).Error; err != nil {
+				return err
+			}
+			if order.UserId != actor.UserId && actor.Role != "admin" {
+				return ErrPaymentForbidden
+			}
+		}
+
+		ticketId := order.TicketId
+		if q.TicketId != nil {
+			ticketId = *q.TicketId
+		}
+		var ticket entities.Ticket
+		if err := tx.Where("id = ?", ticketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if q.TicketId != nil && ticket.Visibility == entities.TicketHidden && actor.Role != "admin" {
+			// hidden tiers are priced for their organizer or with a code that unlocks them
+			var event entities.Event
+			if err := tx.Select("id", "user_id").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+				return err
+			}
+			if event.UserId != actor.UserId {
+				if _, err := accesscodes.Unlock(tx, &ticket, q.AccessCode); err != nil {
+					if errors.Is(err, accesscodes.ErrAccessCodeRequired) || errors.Is(err, accesscodes.ErrInvalidAccessCode) {
+						return gorm.ErrRecordNotFound
+					}
+					return err
+				}
+			}
+		}
+		if q.TicketId != nil {
+			order = entities.Order{
+				UserId:   actor.UserId,
+				TicketId: ticket.ID,
+				Quantity: *q.Quantity,
+				Amount:   int64(*q.Quantity) * ticket.Price,
+				Currency: ticket.Currency,
+			}
+		}
+
+		var discount int64
+		if q.DiscountCode != nil {
+			if order.ListingId.Valid {
+				return discounts.ErrCodeNotApplicable
+			}
+			var err error
+			if discount, err = discounts.Quote(tx, *q.DiscountCode, &order, &ticket); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		breakdown, err = svc.priceOrder(tx, &order, &ticket, discount)
+		return err
+	})
+	if err != nil {
+		return nil, pricing.Breakdown{}, err
+	}
+	return &order, breakdown, nil
+}
@@ -0,0 +1,167 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/ledger"
+	"github.com/rezbow/tickr/internal/passes"
+	"github.com/rezbow/tickr/internal/resale"
+	"github.com/rezbow/tickr/internal/waitlist"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotRefundable         = errors.New("payment is not refundable")
+	ErrInvalidRefundQuantity = errors.New("invalid refund quantity")
+	ErrRefundForbidden       = errors.New("not allowed to refund this payment")
+	ErrRefundPolicyViolation = errors.New("refund not allowed by event refund policy")
+)
+
+// refundPayment refunds quantity units of a payment, or everything not yet
+// refunded when quantity is nil. Organizers may only refund payments for
+// their own events and within the event refund policy; admins may always
+// refund. Inventory, the refund row and the payment status succeed or fail
+// together; the gateway is asked for the money once they are committed, so
+// no rows stay locked while it answers.
+func (svc *PaymentService) refundPayment(ctx context.Context, paymentId uuid.UUID, actor actor, input RefundCreateDTO) (*entities.Refund, *entities.Payment, error) {
+	var refund entities.Refund
+	var payment entities.Payment
+	err := svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, ticket, err := lockPaymentAndTicket(tx, paymentId)
+		if err != nil {
+			return err
+		}
+		payment = *locked
+		if payment.Status != entities.PaymentConfirmed && payment.Status != entities.PaymentPartiallyRefunded {
+			return ErrNotRefundable
+		}
+
+		var event entities.Event
+		if err := tx.Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+			return err
+		}
+
+		if actor.Role != "admin" {
+			if event.UserId != actor.UserId {
+				return ErrRefundForbidden
+			}
+			if !refundAllowed(&event, time.Now()) {
+				return ErrRefundPolicyViolation
+			}
+		}
+
+		remaining := payment.RefundableQuantity()
+		quantity := remaining
+		if input.Quantity != nil {
+			quantity = *input.Quantity
+		}
+		if quantity <= 0 || quantity > remaining {
+			return ErrInvalidRefundQuantity
+		}
+
+		created, err := applyRefund(tx, &payment, ticket, quantity, uuid.NullUUID{UUID: actor.UserId, Valid: true}, input.Reason)
+		if err != nil {
+			return err
+		}
+		refund = *created
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	svc.sendRefund(ctx, payment.ProviderRef, &refund)
+	return &refund, &payment, nil
+}
+
+// sendRefund asks the gateway to hand back a recorded refund of the charge
+// reference and stores the provider's reference to it, which its webhook is
+// recognised by. Failures are logged: a refund of a charged payment left
+// without provider_ref is still owed upstream.
+func (svc *PaymentService) sendRefund(ctx context.Context, reference string, refund *entities.Refund) {
+	// payments made before the gateway existed have nothing to refund upstream
+	if reference == "" || refund.Amount == 0 {
+		return
+	}
+	providerRef, err := svc.gateway.Refund(ctx, reference, refund.Amount)
+	if err != nil {
+		svc.logger.Error("failed refunding payment upstream", "paymentId", refund.PaymentId.String(), "refundId", refund.ID.String(), "error", err.Error())
+		return
+	}
+	refund.ProviderRef = providerRef
+	if err := svc.db.WithContext(ctx).Model(&entities.Refund{}).Where("id = ?", refund.ID).Update("provider_ref", providerRef).Error; err != nil {
+		svc.logger.Error("failed storing refund reference", "refundId", refund.ID.String(), "error", err.Error())
+	}
+}
+
+func refundAllowed(event *entities.Event, now time.Time) bool {
+	switch event.RefundPolicy {
+	case entities.RefundPolicyAnytime:
+		return true
+	case entities.RefundPolicyBeforeStart:
+		return now.Before(event.StartTime)
+	default:
+		return false
+	}
+}
+
+// applyRefund hands quantity units of payment back to ticket and records the
+// refund. Both rows must already be locked by tx.
+func applyRefund(tx *gorm.DB, payment *entities.Payment, ticket *entities.Ticket, quantity int, issuer uuid.NullUUID, reason string) (*entities.Refund, error) {
+	remaining := payment.RefundableQuantity()
+
+	// the last refund takes whatever is left so rounding never strands money,
+	// unless units were resold and their share was paid out to the seller, or
+	// transferred and their share moved to the recipient's payment
+	amount := payment.PaidAmount - payment.RefundedAmount
+	if quantity < remaining || payment.ResoldQuantity > 0 || payment.TransferredQuantity > 0 {
+		amount = payment.PaidAmount * int64(quantity) / int64(payment.Quantity)
+	}
+
+	// listed passes may be among those voided
+	if err := resale.CancelPaymentListings(tx, payment.ID); err != nil {
+		return nil, err
+	}
+
+	ticket.RemainingQuantities += quantity
+	if err := waitlist.OfferReleased(tx, ticket); err != nil {
+		return nil, err
+	}
+	if err := tx.Save(ticket).Error; err != nil {
+		return nil, err
+	}
+	if err := passes.VoidPasses(tx, payment.ID, quantity); err != nil {
+		return nil, err
+	}
+
+	status := entities.PaymentPartiallyRefunded
+	if quantity == remaining {
+		status = entities.PaymentRefunded
+	}
+	payment.RefundedQuantity += quantity
+	payment.RefundedAmount += amount
+	payment.Status = status
+	err := tx.Model(payment).Select("refunded_quantity", "refunded_amount", "status").Updates(payment).Error
+	if err != nil {
+		return nil, err
+	}
+
+	refund := entities.Refund{
+		ID:        uuid.New(),
+		PaymentId: payment.ID,
+		UserId:    issuer,
+		Quantity:  quantity,
+		Amount:    amount,
+		Reason:    reason,
+	}
+	if err := tx.Create(&refund).Error; err != nil {
+		return nil, err
+	}
+	if err := ledger.RecordRefund(tx, payment, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -2,10 +2,21 @@ package payment
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/discounts"
 	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/ledger"
+	"github.com/rezbow/tickr/internal/limits"
+	"github.com/rezbow/tickr/internal/orders"
+	"github.com/rezbow/tickr/internal/passes"
+	"github.com/rezbow/tickr/internal/receipts"
+	"github.com/rezbow/tickr/internal/resale"
+	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/waitlist"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (service *PaymentService) getPayment(ctx context.Context, paymentId uuid.UUID) (*entities.Payment, error) {
@@ -16,6 +27,17 @@ func (service *PaymentService) getPayment(ctx context.Context, paymentId uuid.UU
 	return &payment, nil
 }
 
+// getUserPayments pages through a user's payments, newest first.
+func (service *PaymentService) getUserPayments(ctx context.Context, userId uuid.UUID, p *utils.CursorPagination) ([]entities.Payment, utils.Page, error) {
+	var payments []entities.Payment
+	keyset := utils.Keyset{Name: "created_at", Table: "payment", Column: "payment.created_at", Desc: true}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Where("user_id = ?", userId), p, keyset, func(p *entities.Payment) uuid.UUID { return p.ID }, &payments)
+	if err != nil {
+		return nil, page, err
+	}
+	return payments, page, nil
+}
+
 func (service *PaymentService) getTicket(ctx context.Context, ticketId uuid.UUID) (*entities.Ticket, error) {
 	ticket, err := gorm.G[entities.Ticket](service.db).Where("id = ?", ticketId).First(ctx)
 	if err != nil {
@@ -23,3 +45,238 @@ func (service *PaymentService) getTicket(ctx context.Context, ticketId uuid.UUID
 	}
 	return &ticket, nil
 }
+
+// startPayment records a pending payment for a pending, unexpired order that
+// has no other payment in flight. A promo code given in p is redeemed in the
+// same transaction and taken off the face value before fees and tax.
+func (service *PaymentService) startPayment(ctx context.Context, actor actor, p PaymentDetail) (*entities.Payment, error) {
+	var payment entities.Payment
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order entities.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", p.OrderId).First(&order).Error; err != nil {
+			return err
+		}
+
+		var actedBy uuid.NullUUID
+		if p.OnBehalfOf != nil {
+			if actor.Role != "admin" {
+				return ErrOnBehalfForbidden
+			}
+			if order.UserId != *p.OnBehalfOf {
+				return ErrPaymentForbidden
+			}
+			actedBy = uuid.NullUUID{UUID: actor.UserId, Valid: true}
+		} else if order.UserId != actor.UserId {
+			return ErrPaymentForbidden
+		}
+
+		if order.Status != entities.OrderPending {
+			return ErrOrderNotPending
+		}
+		// expired holds are handed back to the ticket by the orders sweeper
+		if order.ExpiresAt.Before(time.Now()) {
+			return ErrOrderExpired
+		}
+
+		var ticket entities.Ticket
+		if err := tx.Where("id = ?", order.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if order.Currency != ticket.Currency {
+			return ErrCurrencyMismatch
+		}
+		var event entities.Event
+		if err := tx.Select("id", "status").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+			return err
+		}
+		if !event.OnSale() {
+			return orders.ErrNotOnSale
+		}
+		// a hold or waitlist offer doesn't outlive the sale window, resold
+		// passes are sold after it
+		if !order.ListingId.Valid && !ticket.OnSale(time.Now()) {
+			return orders.ErrNotOnSale
+		}
+		// limits may have been lowered, or bypassed by an order placed on behalf
+		if err := limits.Check(tx, order.UserId, &ticket, order.Quantity, order.ID); err != nil {
+			return err
+		}
+
+		var inFlight int64
+		if err := tx.Model(&entities.Payment{}).Where("order_id = ? AND status = ?", order.ID, entities.PaymentPending).Count(&inFlight).Error; err != nil {
+			return err
+		}
+		if inFlight > 0 {
+			return ErrPaymentInProgress
+		}
+
+		payment = entities.Payment{
+			ID:         uuid.New(),
+			UserId:     order.UserId,
+			ActedBy:    actedBy,
+			TicketId:   order.TicketId,
+			OrderId:    order.ID,
+			Quantity:   order.Quantity,
+			Status:     entities.PaymentPending,
+			PaidAmount: order.Amount,
+			FaceAmount: order.Amount,
+			Currency:   order.Currency,
+			Provider:   service.gateway.Name(),
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		var discount int64
+		if p.DiscountCode != nil {
+			// resale prices are set by the seller, not the organizer
+			if order.ListingId.Valid {
+				return discounts.ErrCodeNotApplicable
+			}
+			redemption, err := discounts.Redeem(tx, *p.DiscountCode, &order, &ticket, &payment)
+			if err != nil {
+				return err
+			}
+			payment.DiscountCodeId = uuid.NullUUID{UUID: redemption.DiscountCodeId, Valid: true}
+			discount = redemption.Amount
+		}
+
+		breakdown, err := service.priceOrder(tx, &order, &ticket, discount)
+		if err != nil {
+			return err
+		}
+		payment.DiscountAmount = breakdown.DiscountAmount
+		payment.FeeAmount = breakdown.FeeAmount
+		payment.FeeAbsorbed = breakdown.FeeAbsorbed
+		payment.TaxAmount = breakdown.TaxAmount
+		payment.PaidAmount = breakdown.Total
+		return tx.Model(&payment).
+			Select("discount_code_id", "discount_amount", "fee_amount", "fee_absorbed", "tax_amount", "paid_amount").
+			Updates(&payment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// completePayment confirms a captured payment together with its order,
+// consuming the quantity the order holds on the ticket. Confirming a payment
+// twice, e.g. from checkout and from a provider webhook, is a no-op.
+func (service *PaymentService) completePayment(ctx context.Context, payment *entities.Payment) error {
+	return service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payment.ID).First(payment).Error; err != nil {
+			return err
+		}
+		if payment.Status == entities.PaymentConfirmed {
+			return nil
+		}
+		if payment.Status != entities.PaymentPending {
+			return ErrOrderNotPending
+		}
+
+		var order entities.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payment.OrderId).First(&order).Error; err != nil {
+			return err
+		}
+		// the hold may have been released while the gateway was charging
+		if order.Status != entities.OrderPending {
+			return ErrOrderExpired
+		}
+
+		if err := tx.Model(&order).Update("status", entities.OrderConfirmed).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(payment).Update("status", entities.PaymentConfirmed).Error; err != nil {
+			return err
+		}
+
+		// resale orders buy existing passes off their seller
+		if order.ListingId.Valid {
+			if err := resale.CompleteSale(tx, &order, payment); err != nil {
+				return err
+			}
+			if err := ledger.RecordPayment(tx, payment); err != nil {
+				return err
+			}
+			if err := receipts.IssueInvoice(tx, payment); err != nil {
+				return err
+			}
+			return tx.Where("id = ?", payment.ID).First(payment).Error
+		}
+
+		var ticket entities.Ticket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+
+		ticket.ReservedQuantities -= order.Quantity
+		if err := tx.Save(&ticket).Error; err != nil {
+			return err
+		}
+		if err := waitlist.CloseOffer(tx, order.ID, entities.OrderConfirmed); err != nil {
+			return err
+		}
+		if err := passes.IssuePasses(tx, payment, ticket.EventId); err != nil {
+			return err
+		}
+		if err := ledger.RecordPayment(tx, payment); err != nil {
+			return err
+		}
+		if err := receipts.IssueInvoice(tx, payment); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", payment.ID).First(payment).Error
+	})
+}
+
+func (service *PaymentService) getPaymentByReference(ctx context.Context, provider, reference string) (*entities.Payment, error) {
+	// payments split off for transfers share the charge of the one they came from
+	payment, err := gorm.G[entities.Payment](service.db).Where("provider = ? AND provider_ref = ? AND transferred_from IS NULL", provider, reference).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// lockPaymentAndTicket loads a payment and its ticket, locking both rows.
+func lockPaymentAndTicket(tx *gorm.DB, paymentId uuid.UUID) (*entities.Payment, *entities.Ticket, error) {
+	var payment entities.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentId).First(&payment).Error; err != nil {
+		return nil, nil, err
+	}
+	var ticket entities.Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payment.TicketId).First(&ticket).Error; err != nil {
+		return nil, nil, err
+	}
+	return &payment, &ticket, nil
+}
+
+// recordWebhookEvent stores a provider event unless it was received before,
+// reporting whether it is new. The stored event is returned either way.
+func (service *PaymentService) recordWebhookEvent(ctx context.Context, provider string, payload WebhookPayload, body []byte) (*entities.WebhookEvent, bool, error) {
+	event := entities.WebhookEvent{
+		ID:              uuid.New(),
+		Provider:        provider,
+		ProviderEventId: payload.ID,
+		Type:            payload.Type,
+		Payload:         body,
+		Status:          entities.WebhookReceived,
+	}
+	res := service.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return &event, true, nil
+	}
+	err := service.db.WithContext(ctx).Where("provider = ? AND provider_event_id = ?", provider, payload.ID).First(&event).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &event, false, nil
+}
+
+func (service *PaymentService) updateWebhookEvent(ctx context.Context, event *entities.WebhookEvent) error {
+	return service.db.WithContext(ctx).Model(event).Select("payment_id", "status", "error").Updates(event).Error
+}
@@ -1,59 +1,97 @@
 package payment
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/discounts"
 	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/pricing"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
-var ErrInsuffcientQuantity = errors.New("insufficient quantities")
+var (
+	ErrOrderNotPending   = errors.New("order is not pending")
+	ErrOrderExpired      = errors.New("order hold has expired")
+	ErrPaymentInProgress = errors.New("a payment for this order is already in progress")
+	ErrPaymentForbidden  = errors.New("order belongs to another user")
+	ErrOnBehalfForbidden = errors.New("only admins can pay on behalf of other users")
+	ErrCurrencyMismatch  = errors.New("order currency does not match the ticket's")
+)
+
+// actor is the authenticated user making a request.
+type actor struct {
+	UserId uuid.UUID
+	Role   string
+}
 
 type PaymentService struct {
-	db     *gorm.DB
-	logger *slog.Logger
+	db      *gorm.DB
+	logger  *slog.Logger
+	gateway PaymentGateway
+	fees    pricing.Fees
 }
 
-func NewPaymentService(db *gorm.DB, logger *slog.Logger) *PaymentService {
-	return &PaymentService{db: db, logger: logger}
+func NewPaymentService(db *gorm.DB, logger *slog.Logger, gateway PaymentGateway) *PaymentService {
+	return &PaymentService{db: db, logger: logger, gateway: gateway, fees: pricing.PlatformFees(logger)}
 }
 
-func (svc *PaymentService) createPayment(p PaymentDetail) (*entities.Payment, error) {
-	var payment entities.Payment
-	err := svc.db.Transaction(func(tx *gorm.DB) error {
-		var ticket entities.Ticket
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", p.TicketId).First(&ticket).Error; err != nil {
-			return err
-		}
+// createPayment pays for a pending order owned by the actor, or by
+// p.OnBehalfOf when an admin pays for someone else. A pending payment is
+// recorded first, the amount is authorized and captured through the gateway,
+// and only then are the payment and its order confirmed. Any charge taken for an order that
+// can no longer be confirmed is handed back.
+func (svc *PaymentService) createPayment(ctx context.Context, actor actor, p PaymentDetail) (*entities.Payment, error) {
+	payment, err := svc.startPayment(ctx, actor, p)
+	if err != nil {
+		return nil, err
+	}
 
-		if ticket.RemainingQuantities == 0 || ticket.RemainingQuantities < p.Quantity {
-			return ErrInsuffcientQuantity
-		}
+	reference, err := svc.gateway.Authorize(ctx, AuthorizeRequest{
+		PaymentId: payment.ID,
+		UserId:    payment.UserId,
+		Amount:    payment.PaidAmount,
+	})
+	if err != nil {
+		svc.failPayment(ctx, payment)
+		return nil, err
+	}
+	payment.ProviderRef = reference
+	if err := svc.db.WithContext(ctx).Model(payment).Update("provider_ref", reference).Error; err != nil {
+		svc.logger.Error("failed storing provider reference", "paymentId", payment.ID.String(), "error", err.Error())
+	}
 
-		ticket.RemainingQuantities -= p.Quantity
-		if err := tx.Save(&ticket).Error; err != nil {
-			return err
+	if err := svc.gateway.Capture(ctx, reference, payment.PaidAmount); err != nil {
+		if voidErr := svc.gateway.Void(ctx, reference); voidErr != nil {
+			svc.logger.Error("failed voiding authorization", "paymentId", payment.ID.String(), "error", voidErr.Error())
 		}
+		svc.failPayment(ctx, payment)
+		return nil, err
+	}
 
-		payment := entities.Payment{
-			ID:         uuid.New(),
-			UserId:     p.UserId,
-			TicketId:   p.TicketId,
-			Quantity:   p.Quantity,
-			Status:     entities.PaymentConfirmed,
-			PaidAmount: int64(p.Quantity) * ticket.Price,
-		}
-		if err := tx.Create(&payment).Error; err != nil {
-			return err
+	if err := svc.completePayment(ctx, payment); err != nil {
+		if _, refundErr := svc.gateway.Refund(ctx, reference, payment.PaidAmount); refundErr != nil {
+			svc.logger.Error("failed refunding captured payment", "paymentId", payment.ID.String(), "error", refundErr.Error())
 		}
+		svc.failPayment(ctx, payment)
+		return nil, err
+	}
+	return payment, nil
+}
 
-		if err := tx.Where("id = ?", payment.ID).First(&payment).Error; err != nil {
+// failPayment marks a payment that never completed as canceled and gives
+// back its promo code use.
+func (svc *PaymentService) failPayment(ctx context.Context, payment *entities.Payment) {
+	payment.Status = entities.PaymentCanceled
+	err := svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(payment).Update("status", entities.PaymentCanceled).Error; err != nil {
 			return err
 		}
-		return nil
+		return discounts.Release(tx, payment.ID)
 	})
-	return &payment, err
+	if err != nil {
+		svc.logger.Error("failed canceling payment", "paymentId", payment.ID.String(), "error", err.Error())
+	}
 }
@@ -0,0 +1,271 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/discounts"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookSignatureHeader = "Tickr-Signature"
+	// signatures older or newer than this are rejected to stop replays
+	webhookTolerance = 5 * time.Minute
+
+	WebhookPaymentCaptured = "payment.captured"
+	WebhookPaymentFailed   = "payment.failed"
+	WebhookPaymentVoided   = "payment.voided"
+	WebhookPaymentRefunded = "payment.refunded"
+)
+
+var (
+	ErrInvalidSignature  = errors.New("invalid webhook signature")
+	ErrInvalidTransition = errors.New("payment status transition not allowed")
+	ErrInvalidRefund     = errors.New("refund event without a refund reference or amount")
+)
+
+var providerPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// webhookTargets maps provider event types to the payment status they lead to.
+var webhookTargets = map[string]string{
+	WebhookPaymentCaptured: entities.PaymentConfirmed,
+	WebhookPaymentFailed:   entities.PaymentCanceled,
+	WebhookPaymentVoided:   entities.PaymentCanceled,
+	WebhookPaymentRefunded: entities.PaymentRefunded,
+}
+
+// paymentTransitions lists the statuses a payment may move to from each status.
+var paymentTransitions = map[string][]string{
+	entities.PaymentPending:           {entities.PaymentConfirmed, entities.PaymentCanceled},
+	entities.PaymentConfirmed:         {entities.PaymentRefunded},
+	entities.PaymentPartiallyRefunded: {entities.PaymentRefunded},
+}
+
+func canTransition(from, to string) bool {
+	return slices.Contains(paymentTransitions[from], to)
+}
+
+// WebhookPayload is the provider-neutral shape of a payment webhook.
+type WebhookPayload struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		// Reference is the gateway reference stored as Payment.ProviderRef
+		Reference string `json:"reference"`
+		// RefundReference and Amount describe the refund of a payment.refunded
+		// event, Amount in the smallest currency unit
+		RefundReference string `json:"refund_reference"`
+		Amount          int64  `json:"amount"`
+	} `json:"data"`
+}
+
+// WebhookSecret returns the signing secret configured for provider through
+// PAYMENT_WEBHOOK_SECRET_<PROVIDER>, or nil if there is none.
+func WebhookSecret(provider string) []byte {
+	if !providerPattern.MatchString(provider) {
+		return nil
+	}
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET_" + strings.ToUpper(provider))
+	if secret == "" {
+		return nil
+	}
+	return []byte(secret)
+}
+
+// SignWebhook returns the Tickr-Signature header value for body, an HMAC-SHA256
+// over "<unix timestamp>.<body>".
+func SignWebhook(secret []byte, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+// VerifyWebhookSignature checks a Tickr-Signature header against body.
+func VerifyWebhookSignature(secret []byte, header string, body []byte, now time.Time) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age > webhookTolerance || age < -webhookTolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := []byte(webhookMAC(secret, ts, body))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func webhookMAC(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// handleWebhookEvent logs a verified provider event and applies it to the
+// payment it references. Events already handled are reported as duplicates;
+// events that failed earlier are retried.
+func (svc *PaymentService) handleWebhookEvent(ctx context.Context, provider string, payload WebhookPayload, body []byte) (string, error) {
+	event, created, err := svc.recordWebhookEvent(ctx, provider, payload, body)
+	if err != nil {
+		return "", err
+	}
+	if !created && (event.Status == entities.WebhookProcessed || event.Status == entities.WebhookIgnored) {
+		return "duplicate", nil
+	}
+
+	paymentId, applyErr := svc.applyWebhookEvent(ctx, provider, payload)
+	event.PaymentId = paymentId
+	event.Error = ""
+	switch {
+	case applyErr == nil:
+		event.Status = entities.WebhookProcessed
+	case errors.Is(applyErr, ErrInvalidTransition), errors.Is(applyErr, ErrInvalidRefund):
+		event.Status = entities.WebhookIgnored
+		event.Error = applyErr.Error()
+	default:
+		event.Status = entities.WebhookFailed
+		event.Error = applyErr.Error()
+	}
+	if err := svc.updateWebhookEvent(ctx, event); err != nil {
+		return "", err
+	}
+	if event.Status == entities.WebhookFailed {
+		return "", applyErr
+	}
+	return event.Status, nil
+}
+
+// applyWebhookEvent moves the referenced payment to the status the event
+// type leads to. Unknown event types and statuses already reached are no-ops.
+func (svc *PaymentService) applyWebhookEvent(ctx context.Context, provider string, payload WebhookPayload) (uuid.NullUUID, error) {
+	target, ok := webhookTargets[payload.Type]
+	if !ok {
+		return uuid.NullUUID{}, nil
+	}
+
+	payment, err := svc.getPaymentByReference(ctx, provider, payload.Data.Reference)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	paymentId := uuid.NullUUID{UUID: payment.ID, Valid: true}
+	if payment.Status == target {
+		return paymentId, nil
+	}
+	if !canTransition(payment.Status, target) {
+		return paymentId, ErrInvalidTransition
+	}
+
+	switch target {
+	case entities.PaymentConfirmed:
+		err = svc.completePayment(ctx, payment)
+		if errors.Is(err, ErrOrderExpired) {
+			if _, refundErr := svc.gateway.Refund(ctx, payment.ProviderRef, payment.PaidAmount); refundErr != nil {
+				svc.logger.Error("failed refunding captured payment", "paymentId", payment.ID.String(), "error", refundErr.Error())
+			}
+			svc.failPayment(ctx, payment)
+			return paymentId, nil
+		}
+	case entities.PaymentCanceled:
+		err = svc.cancelPendingPayment(ctx, payment.ID)
+	case entities.PaymentRefunded:
+		err = svc.refundFromProvider(ctx, payment.ID, payload.Data.RefundReference, payload.Data.Amount)
+	}
+	return paymentId, err
+}
+
+// cancelPendingPayment cancels a payment the provider reports as failed.
+func (svc *PaymentService) cancelPendingPayment(ctx context.Context, paymentId uuid.UUID) error {
+	return svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entities.Payment{}).
+			Where("id = ? AND status = ?", paymentId, entities.PaymentPending).
+			Update("status", entities.PaymentCanceled)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+		return discounts.Release(tx, paymentId)
+	})
+}
+
+// refundFromProvider records a refund the provider issued on its own, such as
+// a chargeback, for the units amount pays for. Refunds the platform issued
+// itself are already recorded and are recognised by their reference.
+func (svc *PaymentService) refundFromProvider(ctx context.Context, paymentId uuid.UUID, refundReference string, amount int64) error {
+	if refundReference == "" || amount <= 0 {
+		return ErrInvalidRefund
+	}
+	return svc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		payment, ticket, err := lockPaymentAndTicket(tx, paymentId)
+		if err != nil {
+			return err
+		}
+		var issued int64
+		if err := tx.Model(&entities.Refund{}).Where("provider_ref = ?", refundReference).Count(&issued).Error; err != nil {
+			return err
+		}
+		if issued > 0 {
+			return nil
+		}
+		if !canTransition(payment.Status, entities.PaymentRefunded) {
+			return ErrInvalidTransition
+		}
+		quantity := refundedUnits(payment, amount)
+		if quantity == 0 {
+			return ErrInvalidTransition
+		}
+		refund, err := applyRefund(tx, payment, ticket, quantity, uuid.NullUUID{}, "refunded by payment provider")
+		if err != nil {
+			return err
+		}
+		return tx.Model(refund).Update("provider_ref", refundReference).Error
+	})
+}
+
+// refundedUnits is how many of a payment's refundable units amount pays for,
+// rounded to the nearest unit but at least one. An amount covering what is
+// left refunds everything.
+func refundedUnits(payment *entities.Payment, amount int64) int {
+	remaining := payment.RefundableQuantity()
+	if remaining == 0 || payment.Quantity == 0 || payment.PaidAmount == 0 {
+		return remaining
+	}
+	if amount >= payment.PaidAmount-payment.RefundedAmount {
+		return remaining
+	}
+	units := int((amount*int64(payment.Quantity) + payment.PaidAmount/2) / payment.PaidAmount)
+	return min(max(units, 1), remaining)
+}
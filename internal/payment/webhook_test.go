@@ -0,0 +1,120 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := []byte("whsec_test")
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	now := time.Unix(1_800_000_000, 0)
+	valid := SignWebhook(secret, now, body)
+	mac := valid[len("t=1800000000,v1="):]
+	other := SignWebhook([]byte("whsec_other"), now, body)[len("t=1800000000,v1="):]
+
+	tests := []struct {
+		name   string
+		header string
+		body   []byte
+		want   error
+	}{
+		{name: "valid", header: valid, body: body},
+		{name: "spaces after commas", header: "t=1800000000, v1=" + mac, body: body},
+		{name: "one of several v1 values", header: "t=1800000000,v1=" + other + ",v1=" + mac, body: body},
+		{name: "unknown keys ignored", header: "t=1800000000,v0=abc,v1=" + mac, body: body},
+		{name: "signed by another secret", header: "t=1800000000,v1=" + other, body: body, want: ErrInvalidSignature},
+		{name: "none of several v1 values", header: "t=1800000000,v1=" + other + ",v1=deadbeef", body: body, want: ErrInvalidSignature},
+		{name: "body changed", header: valid, body: []byte(`{"id":"evt_2","type":"payment.succeeded"}`), want: ErrInvalidSignature},
+		{name: "timestamp changed", header: "t=1800000001,v1=" + mac, body: body, want: ErrInvalidSignature},
+		{name: "stale", header: SignWebhook(secret, now.Add(-webhookTolerance-time.Second), body), body: body, want: ErrInvalidSignature},
+		{name: "from the future", header: SignWebhook(secret, now.Add(webhookTolerance+time.Second), body), body: body, want: ErrInvalidSignature},
+		{name: "oldest accepted", header: SignWebhook(secret, now.Add(-webhookTolerance), body), body: body},
+		{name: "latest accepted", header: SignWebhook(secret, now.Add(webhookTolerance), body), body: body},
+		{name: "no timestamp", header: "v1=" + mac, body: body, want: ErrInvalidSignature},
+		{name: "malformed timestamp", header: "t=yesterday,v1=" + mac, body: body, want: ErrInvalidSignature},
+		{name: "no signature", header: "t=1800000000", body: body, want: ErrInvalidSignature},
+		{name: "empty header", header: "", body: body, want: ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyWebhookSignature(secret, tt.header, tt.body, now); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyWebhookSignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundedUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment entities.Payment
+		amount  int64
+		want    int
+	}{
+		{
+			name:    "one unit's share",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000},
+			amount:  2500,
+			want:    1,
+		},
+		{
+			name:    "rounds to the nearest unit",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000},
+			amount:  6300,
+			want:    3,
+		},
+		{
+			name:    "at least one unit",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000},
+			amount:  100,
+			want:    1,
+		},
+		{
+			name:    "everything left",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000, RefundedQuantity: 1, RefundedAmount: 2500},
+			amount:  7500,
+			want:    3,
+		},
+		{
+			name:    "more than is left",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000, RefundedQuantity: 1, RefundedAmount: 2500},
+			amount:  50000,
+			want:    3,
+		},
+		{
+			name:    "capped at the refundable units",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000, ResoldQuantity: 2},
+			amount:  7500,
+			want:    2,
+		},
+		{
+			name:    "transferred units are not refundable",
+			payment: entities.Payment{Quantity: 4, PaidAmount: 10000, TransferredQuantity: 3},
+			amount:  5000,
+			want:    1,
+		},
+		{
+			name:    "nothing left",
+			payment: entities.Payment{Quantity: 2, PaidAmount: 5000, RefundedQuantity: 2, RefundedAmount: 5000},
+			amount:  2500,
+			want:    0,
+		},
+		{
+			name:    "free units",
+			payment: entities.Payment{Quantity: 3},
+			amount:  0,
+			want:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refundedUnits(&tt.payment, tt.amount); got != tt.want {
+				t.Errorf("refundedUnits() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+// Fees is the platform's charge on a purchase: Fixed per unit plus Rate
+// basis points of the amount paid for the units.
+type Fees struct {
+	Fixed int64
+	Rate  int64
+}
+
+// PlatformFees reads the platform fees from PLATFORM_FEE_FIXED, in minor
+// units of the ticket's currency per ticket, and PLATFORM_FEE_RATE, in basis
+// points. Both default to zero.
+func PlatformFees(logger *slog.Logger) Fees {
+	var fees Fees
+	if value := os.Getenv("PLATFORM_FEE_FIXED"); value != "" {
+		fixed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || fixed < 0 {
+			logger.Warn("invalid PLATFORM_FEE_FIXED, charging no fixed fee", "value", value)
+		} else {
+			fees.Fixed = fixed
+		}
+	}
+	if value := os.Getenv("PLATFORM_FEE_RATE"); value != "" {
+		rate, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rate < 0 || rate > 10000 {
+			logger.Warn("invalid PLATFORM_FEE_RATE, charging no percentage fee", "value", value)
+		} else {
+			fees.Rate = rate
+		}
+	}
+	return fees
+}
+
+// Breakdown splits what a buyer pays into face value, discount, platform fee
+// and tax.
+type Breakdown struct {
+	FaceAmount     int64 // units times unit price
+	DiscountAmount int64
+	FeeAmount      int64
+	// FeeAbsorbed is set when the organizer pays the fee out of the face
+	// value instead of the buyer on top of it
+	FeeAbsorbed bool
+	TaxAmount   int64
+	Total       int64 // charged to the buyer
+}
+
+// Subtotal is the face value after the discount.
+func (b Breakdown) Subtotal() int64 {
+	return b.FaceAmount - b.DiscountAmount
+}
+
+// Price prices quantity units worth faceAmount at face value for event,
+// taking discount off before fees and tax. Units given away for free carry
+// no fee.
+func Price(fees Fees, event *entities.Event, quantity int, faceAmount, discount int64) Breakdown {
+	b := Breakdown{
+		FaceAmount:     faceAmount,
+		DiscountAmount: discount,
+		FeeAbsorbed:    event.FeeMode == entities.FeeModeAbsorbed,
+	}
+	subtotal := b.Subtotal()
+	if subtotal > 0 {
+		b.FeeAmount = min(fees.Fixed*int64(quantity)+subtotal*fees.Rate/10000, subtotal)
+	}
+	b.TaxAmount = subtotal * int64(event.TaxRate) / 10000
+
+	b.Total = subtotal + b.TaxAmount
+	if !b.FeeAbsorbed {
+		b.Total += b.FeeAmount
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/rezbow/tickr/internal/entities"
+)
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		fees     Fees
+		event    entities.Event
+		quantity int
+		face     int64
+		discount int64
+		want     Breakdown
+	}{
+		{
+			name:     "no fees or tax",
+			quantity: 2,
+			face:     5000,
+			want:     Breakdown{FaceAmount: 5000, Total: 5000},
+		},
+		{
+			name:     "fee and tax on top",
+			fees:     Fees{Fixed: 50, Rate: 250},
+			event:    entities.Event{FeeMode: entities.FeeModePassThrough, TaxRate: 1000},
+			quantity: 2,
+			face:     10000,
+			want:     Breakdown{FaceAmount: 10000, FeeAmount: 350, TaxAmount: 1000, Total: 11350},
+		},
+		{
+			name:     "fee absorbed by the organizer",
+			fees:     Fees{Fixed: 50, Rate: 250},
+			event:    entities.Event{FeeMode: entities.FeeModeAbsorbed, TaxRate: 1000},
+			quantity: 2,
+			face:     10000,
+			want:     Breakdown{FaceAmount: 10000, FeeAmount: 350, FeeAbsorbed: true, TaxAmount: 1000, Total: 11000},
+		},
+		{
+			name:     "discount comes off before fee and tax",
+			fees:     Fees{Rate: 1000},
+			event:    entities.Event{TaxRate: 500},
+			quantity: 1,
+			face:     10000,
+			discount: 2000,
+			want:     Breakdown{FaceAmount: 10000, DiscountAmount: 2000, FeeAmount: 800, TaxAmount: 400, Total: 9200},
+		},
+		{
+			name:     "free units carry no fee",
+			fees:     Fees{Fixed: 50, Rate: 250},
+			event:    entities.Event{TaxRate: 1000},
+			quantity: 1,
+			face:     1000,
+			discount: 1000,
+			want:     Breakdown{FaceAmount: 1000, DiscountAmount: 1000},
+		},
+		{
+			name:     "fee capped at the subtotal",
+			fees:     Fees{Fixed: 500},
+			quantity: 1,
+			face:     100,
+			want:     Breakdown{FaceAmount: 100, FeeAmount: 100, Total: 200},
+		},
+		{
+			name:     "fractions of a minor unit round down",
+			fees:     Fees{Rate: 250},
+			event:    entities.Event{TaxRate: 825},
+			quantity: 1,
+			face:     333,
+			want:     Breakdown{FaceAmount: 333, FeeAmount: 8, TaxAmount: 27, Total: 368},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Price(tt.fees, &tt.event, tt.quantity, tt.face, tt.discount)
+			if got != tt.want {
+				t.Errorf("Price() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
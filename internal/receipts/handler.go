@@ -0,0 +1,42 @@
+package receipts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (service *ReceiptsService) GetReceiptHandler(c *gin.Context) {
+	paymentId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+
+	receipt, err := service.issueReceipt(c.Request.Context(), paymentId)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, ErrNotPaid), errors.Is(err, ErrTransferred):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed issuing receipt", "paymentId", paymentId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	body, err := render(receipt)
+	if err != nil {
+		service.logger.Error("failed rendering receipt", "paymentId", paymentId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%s.pdf"`, InvoiceNumber(&receipt.Invoice)))
+	c.Data(http.StatusOK, "application/pdf", body)
+}
@@ -0,0 +1,131 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rezbow/tickr/internal/currency"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// InvoiceNumber renders an invoice number, prefixed with the organizer so
+// numbers of different organizers never collide.
+func InvoiceNumber(invoice *entities.Invoice) string {
+	prefix := strings.ToUpper(invoice.OrganizerId.String()[:8])
+	return fmt.Sprintf("%s-%06d", prefix, invoice.Number)
+}
+
+// render lays the receipt out on a single A4 page, in the Go fonts, which
+// cover the Latin, Greek and Cyrillic scripts names and titles come in.
+func render(r *receipt) ([]byte, error) {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4, Unit: gopdf.UnitMM})
+	pdf.SetMargins(10, 10, 10, 10)
+	pdf.SetInfo(gopdf.PdfInfo{Title: "Receipt " + InvoiceNumber(&r.Invoice), Author: "tickr"})
+	if err := pdf.AddTTFFontData("regular", goregular.TTF); err != nil {
+		return nil, err
+	}
+	if err := pdf.AddTTFFontData("bold", gobold.TTF); err != nil {
+		return nil, err
+	}
+	pdf.AddPage()
+
+	// errors of the font and text calls are kept until the page is written,
+	// as the first one makes the rest fail the same way
+	var err error
+	font := func(family string, size float64) {
+		if err == nil {
+			err = pdf.SetFont(family, "", size)
+		}
+	}
+	cell := func(width, height float64, text string, align int, border int) {
+		if err == nil {
+			err = pdf.CellWithOption(&gopdf.Rect{W: width, H: height}, text, gopdf.CellOption{
+				Align:  align | gopdf.Middle,
+				Border: border,
+				Float:  gopdf.Right,
+			})
+		}
+	}
+
+	font("bold", 18)
+	cell(190, 10, "Receipt", gopdf.Left, 0)
+	pdf.Br(12)
+
+	field := func(label, value string) {
+		font("bold", 10)
+		cell(40, 6, label, gopdf.Left, 0)
+		font("regular", 10)
+		cell(150, 6, value, gopdf.Left, 0)
+		pdf.Br(6)
+	}
+	field("Invoice number", InvoiceNumber(&r.Invoice))
+	field("Issued", r.Invoice.CreatedAt.UTC().Format("2006-01-02"))
+	field("Payment ID", r.Payment.ID.String())
+	field("Paid on", r.Payment.CreatedAt.UTC().Format(time.RFC1123))
+	field("Status", r.Payment.Status)
+	pdf.Br(4)
+
+	field("Billed to", r.Buyer.Name)
+	field("", r.Buyer.Email)
+	field("Sold by", r.Seller.Name)
+	if r.Seller.ID != r.Organizer.ID {
+		field("Organized by", r.Organizer.Name)
+	}
+	pdf.Br(4)
+
+	field("Event", r.Event.Title)
+	field("Venue", r.Event.Venue)
+	field("Starts", r.Event.StartTime.UTC().Format(time.RFC1123))
+	field("Ends", r.Event.EndTime.UTC().Format(time.RFC1123))
+	pdf.Br(6)
+
+	code := r.Payment.Currency
+	pdf.SetFillColor(230, 230, 230)
+	pdf.RectFromUpperLeftWithStyle(pdf.GetX(), pdf.GetY(), 190, 7, "F")
+	font("bold", 10)
+	cell(110, 7, "Description", gopdf.Left, gopdf.AllBorders)
+	cell(20, 7, "Qty", gopdf.Right, gopdf.AllBorders)
+	cell(60, 7, "Amount", gopdf.Right, gopdf.AllBorders)
+	pdf.Br(7)
+	font("regular", 10)
+	line := func(description string, quantity string, amount int64) {
+		cell(110, 7, description, gopdf.Left, gopdf.AllBorders)
+		cell(20, 7, quantity, gopdf.Right, gopdf.AllBorders)
+		cell(60, 7, currency.Format(amount, code), gopdf.Right, gopdf.AllBorders)
+		pdf.Br(7)
+	}
+	line(fmt.Sprintf("%s at %s each", r.Ticket.Name, currency.Format(r.UnitPrice, code)), fmt.Sprint(r.Payment.Quantity), r.Payment.FaceAmount)
+	if r.Payment.DiscountAmount > 0 {
+		line("Discount", "", -r.Payment.DiscountAmount)
+	}
+	if r.Payment.FeeAmount > 0 && !r.Payment.FeeAbsorbed {
+		line("Service fee", "", r.Payment.FeeAmount)
+	}
+	if r.Payment.TaxAmount > 0 {
+		line("Tax", "", r.Payment.TaxAmount)
+	}
+
+	font("bold", 10)
+	cell(130, 7, "Total paid", gopdf.Left, gopdf.AllBorders)
+	cell(60, 7, currency.Format(r.Payment.PaidAmount, code), gopdf.Right, gopdf.AllBorders)
+	pdf.Br(7)
+	if r.Payment.RefundedAmount > 0 {
+		font("regular", 10)
+		line("Refunded", fmt.Sprint(r.Payment.RefundedQuantity), -r.Payment.RefundedAmount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
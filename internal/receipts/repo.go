@@ -0,0 +1,112 @@
+package receipts
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// receipt is everything printed on a payment's receipt. Resold passes are
+// sold by Seller at the listing's price, others by the organizer at the
+// ticket's.
+type receipt struct {
+	Invoice   entities.Invoice
+	Payment   entities.Payment
+	Buyer     entities.User
+	Organizer entities.User
+	Seller    entities.User
+	Ticket    entities.Ticket
+	Event     entities.Event
+	UnitPrice int64
+}
+
+// IssueInvoice numbers the invoice of a payment in the sequence of the
+// organizer whose event was paid for, in the order payments are confirmed.
+// The counter row is locked so concurrent payments never share a number.
+func IssueInvoice(tx *gorm.DB, payment *entities.Payment) error {
+	var ticket entities.Ticket
+	if err := tx.Preload("Event").Where("id = ?", payment.TicketId).First(&ticket).Error; err != nil {
+		return err
+	}
+	organizerId := ticket.Event.UserId
+
+	counter := entities.InvoiceCounter{UserId: organizerId}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+		return err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", organizerId).First(&counter).Error; err != nil {
+		return err
+	}
+	counter.LastNumber++
+	if err := tx.Model(&counter).Update("last_number", counter.LastNumber).Error; err != nil {
+		return err
+	}
+	return tx.Create(&entities.Invoice{
+		ID:          uuid.New(),
+		PaymentId:   payment.ID,
+		OrganizerId: organizerId,
+		Number:      counter.LastNumber,
+	}).Error
+}
+
+// issueReceipt gathers the receipt of a completed payment, whose invoice was
+// numbered when it was confirmed.
+func (service *ReceiptsService) issueReceipt(ctx context.Context, paymentId uuid.UUID) (*receipt, error) {
+	var r receipt
+	db := service.db.WithContext(ctx)
+	if err := db.Where("id = ?", paymentId).First(&r.Payment).Error; err != nil {
+		return nil, err
+	}
+	// payments canceled with their event were paid and refunded
+	paid := []string{entities.PaymentConfirmed, entities.PaymentPartiallyRefunded, entities.PaymentRefunded}
+	refunded := r.Payment.Status == entities.PaymentCanceled && r.Payment.RefundedAmount > 0
+	if !slices.Contains(paid, r.Payment.Status) && !refunded {
+		return nil, ErrNotPaid
+	}
+	if r.Payment.TransferredFrom.Valid {
+		return nil, ErrTransferred
+	}
+	err := db.Where("payment_id = ?", paymentId).First(&r.Invoice).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotPaid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("id = ?", r.Payment.UserId).First(&r.Buyer).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("id = ?", r.Payment.TicketId).First(&r.Ticket).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("id = ?", r.Ticket.EventId).First(&r.Event).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("id = ?", r.Event.UserId).First(&r.Organizer).Error; err != nil {
+		return nil, err
+	}
+
+	r.Seller, r.UnitPrice = r.Organizer, r.Ticket.Price
+	var sale entities.ResaleSale
+	err = db.Where("payment_id = ?", paymentId).First(&sale).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var listing entities.Listing
+	if err := db.Select("id", "price").Where("id = ?", sale.ListingId).First(&listing).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("id = ?", sale.SellerId).First(&r.Seller).Error; err != nil {
+		return nil, err
+	}
+	r.UnitPrice = listing.Price
+	return &r, nil
+}
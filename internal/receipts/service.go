@@ -0,0 +1,22 @@
+package receipts
+
+import (
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotPaid     = errors.New("payment has not been completed")
+	ErrTransferred = errors.New("passes received by transfer have no receipt, the sender's payment has it")
+)
+
+type ReceiptsService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewReceiptsService(db *gorm.DB, logger *slog.Logger) *ReceiptsService {
+	return &ReceiptsService{db: db, logger: logger}
+}
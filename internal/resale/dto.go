@@ -0,0 +1,118 @@
+package resale
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+type ListingCreateDTO struct {
+	PaymentId uuid.UUID `json:"payment_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required"`
+	Price     int64     `json:"price" binding:"required"`
+}
+
+func (l *ListingCreateDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(l.Quantity > 0, "quantity", "must be positive integer")
+	v.Must(l.Price > 0, "price", "must be positive integer")
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type ListingOrderDTO struct {
+	Quantity int `json:"quantity" binding:"required"`
+}
+
+func (l *ListingOrderDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(l.Quantity > 0, "quantity", "must be positive integer")
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type Listing struct {
+	ID        uuid.UUID `json:"id"`
+	UserId    uuid.UUID `json:"user_id"`
+	TicketId  uuid.UUID `json:"ticket_id"`
+	EventId   uuid.UUID `json:"event_id"`
+	Price     int64     `json:"price"`
+	Currency  string    `json:"currency"`
+	Available int       `json:"available"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ListingEntityToListing(l *entities.Listing) Listing {
+	return Listing{
+		ID:        l.ID,
+		UserId:    l.UserId,
+		TicketId:  l.TicketId,
+		EventId:   l.EventId,
+		Price:     l.Price,
+		Currency:  l.Currency,
+		Available: l.Available(),
+		Status:    l.Status,
+		CreatedAt: l.CreatedAt,
+	}
+}
+
+func ListingEntitiesToListings(listings []entities.Listing) []Listing {
+	result := make([]Listing, len(listings))
+	for i := range listings {
+		result[i] = ListingEntityToListing(&listings[i])
+	}
+	return result
+}
+
+// SellerListing is how sellers see their own listings.
+type SellerListing struct {
+	Listing
+	PaymentId    uuid.UUID `json:"payment_id"`
+	Quantity     int       `json:"quantity"`
+	SoldQuantity int       `json:"sold_quantity"`
+	Proceeds     int64     `json:"proceeds"` // after the platform fee
+}
+
+func sellerListingsToSellerListings(listings []sellerListing) []SellerListing {
+	result := make([]SellerListing, len(listings))
+	for i := range listings {
+		l := &listings[i]
+		result[i] = SellerListing{
+			Listing:      ListingEntityToListing(&l.Listing),
+			PaymentId:    l.PaymentId,
+			Quantity:     l.Quantity,
+			SoldQuantity: l.SoldQuantity,
+			Proceeds:     l.Proceeds,
+		}
+	}
+	return result
+}
+
+type Order struct {
+	ID        uuid.UUID `json:"id"`
+	ListingId uuid.UUID `json:"listing_id"`
+	TicketId  uuid.UUID `json:"ticket_id"`
+	Quantity  int       `json:"quantity"`
+	Amount    int64     `json:"amount"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func OrderEntityToOrder(o *entities.Order) Order {
+	return Order{
+		ID:        o.ID,
+		ListingId: o.ListingId.UUID,
+		TicketId:  o.TicketId,
+		Quantity:  o.Quantity,
+		Amount:    o.Amount,
+		Status:    o.Status,
+		ExpiresAt: o.ExpiresAt,
+	}
+}
@@ -0,0 +1,208 @@
+package resale
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/passes"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// createListing sets aside input.Quantity of the seller's unused passes from
+// one of their payments and lists them at input.Price a unit. The seller's
+// row is locked so transfers and other listings cannot claim the same passes.
+func (service *ResaleService) createListing(ctx context.Context, sellerId uuid.UUID, input ListingCreateDTO) (*entities.Listing, error) {
+	var listing entities.Listing
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var seller entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", sellerId).First(&seller).Error; err != nil {
+			return err
+		}
+
+		var payment entities.Payment
+		if err := tx.Where("id = ?", input.PaymentId).First(&payment).Error; err != nil {
+			return err
+		}
+		if payment.UserId != sellerId {
+			return ErrNotPaymentHolder
+		}
+
+		var ticket entities.Ticket
+		if err := tx.Preload("Event").Where("id = ?", payment.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if !ticket.Event.ResaleEnabled {
+			return ErrResaleDisabled
+		}
+		if !ticket.Event.OnSale() {
+			return ErrEventNotOnSale
+		}
+		now := time.Now()
+		if !now.Before(ticket.Event.StartTime) {
+			return ErrResaleClosed
+		}
+		if input.Price > PriceCap(ticket.Price, ticket.Event.ResaleMaxMarkup) {
+			return ErrPriceAboveCap
+		}
+
+		var ids []uuid.UUID
+		err := tx.Model(&entities.Pass{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("payment_id = ? AND user_id = ? AND status = ?", payment.ID, sellerId, entities.PassValid).
+			Scopes(passes.Unencumbered(now)).
+			Order("created_at ASC").
+			Limit(input.Quantity).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) < input.Quantity {
+			return ErrNotEnoughPasses
+		}
+
+		listing = entities.Listing{
+			ID:        uuid.New(),
+			UserId:    sellerId,
+			PaymentId: payment.ID,
+			TicketId:  ticket.ID,
+			EventId:   ticket.EventId,
+			Quantity:  input.Quantity,
+			Price:     input.Price,
+			Currency:  ticket.Currency,
+			Status:    entities.ListingActive,
+			Passes:    make([]entities.ListingPass, len(ids)),
+		}
+		for i, id := range ids {
+			listing.Passes[i] = entities.ListingPass{ID: uuid.New(), ListingId: listing.ID, PassId: id}
+		}
+		return tx.Create(&listing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// cancelListing withdraws a listing, releasing its unsold passes. Listings
+// with units held by buyers cannot be withdrawn until the holds end.
+func (service *ResaleService) cancelListing(ctx context.Context, listingId uuid.UUID) (*entities.Listing, error) {
+	var listing entities.Listing
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", listingId).First(&listing).Error; err != nil {
+			return err
+		}
+		if listing.Status != entities.ListingActive {
+			return ErrListingNotActive
+		}
+		if listing.ReservedQuantity > 0 {
+			return ErrListingReserved
+		}
+		listing.Status = entities.ListingCanceled
+		return tx.Model(&listing).Update("status", entities.ListingCanceled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// createListingOrder holds quantity units of a listing for the buyer. The
+// order is paid through POST /payments like any other.
+func (service *ResaleService) createListingOrder(ctx context.Context, buyerId, listingId uuid.UUID, quantity int) (*entities.Order, error) {
+	var order entities.Order
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var listing entities.Listing
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", listingId).First(&listing).Error; err != nil {
+			return err
+		}
+		if listing.Status != entities.ListingActive {
+			return ErrListingNotActive
+		}
+		if listing.UserId == buyerId {
+			return ErrOwnListing
+		}
+		if listing.Available() < quantity {
+			return ErrNotEnoughAvailable
+		}
+
+		var event entities.Event
+		if err := tx.Select("id", "start_time", "status").Where("id = ?", listing.EventId).First(&event).Error; err != nil {
+			return err
+		}
+		if !event.OnSale() {
+			return ErrEventNotOnSale
+		}
+		now := time.Now()
+		if !now.Before(event.StartTime) {
+			return ErrResaleClosed
+		}
+
+		listing.ReservedQuantity += quantity
+		if err := tx.Model(&listing).Update("reserved_quantity", listing.ReservedQuantity).Error; err != nil {
+			return err
+		}
+
+		order = entities.Order{
+			ID:        uuid.New(),
+			UserId:    buyerId,
+			TicketId:  listing.TicketId,
+			ListingId: uuid.NullUUID{UUID: listing.ID, Valid: true},
+			Quantity:  quantity,
+			Amount:    int64(quantity) * listing.Price,
+			Currency:  listing.Currency,
+			Status:    entities.OrderPending,
+			ExpiresAt: now.Add(service.holdWindow),
+		}
+		return tx.Create(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// getEventListings lists the active listings of an event with units left,
+// cheapest first, to whom the event is visible.
+func (service *ResaleService) getEventListings(ctx context.Context, eventId, userId uuid.UUID, role string, p *utils.CursorPagination) ([]entities.Listing, utils.Page, error) {
+	var event entities.Event
+	if err := service.db.WithContext(ctx).Select("id", "user_id", "status").Where("id = ?", eventId).First(&event).Error; err != nil {
+		return nil, utils.Page{}, err
+	}
+	if !event.VisibleTo(userId, role) {
+		return nil, utils.Page{}, gorm.ErrRecordNotFound
+	}
+
+	db := service.db.WithContext(ctx).
+		Where("event_id = ? AND status = ? AND quantity > reserved_quantity + sold_quantity", eventId, entities.ListingActive)
+	var listings []entities.Listing
+	keyset := utils.Keyset{Name: "price", Table: "listings", Column: "listings.price"}
+	page, err := utils.CursorPaginate(db, p, keyset, func(l *entities.Listing) uuid.UUID { return l.ID }, &listings)
+	if err != nil {
+		return nil, page, err
+	}
+	return listings, page, nil
+}
+
+// sellerListing is a listing with what its sales earned the seller.
+type sellerListing struct {
+	entities.Listing `gorm:"embedded"`
+	Proceeds         int64
+}
+
+// getUserListings pages through a seller's listings, newest first.
+func (service *ResaleService) getUserListings(ctx context.Context, userId uuid.UUID, p *utils.CursorPagination) ([]sellerListing, utils.Page, error) {
+	db := service.db.WithContext(ctx).Model(&entities.Listing{}).
+		Select("listings.*, (SELECT COALESCE(SUM(proceeds), 0) FROM resale_sales WHERE resale_sales.listing_id = listings.id) AS proceeds").
+		Where("user_id = ?", userId)
+	var listings []sellerListing
+	keyset := utils.Keyset{Name: "created_at", Table: "listings", Column: "listings.created_at", Desc: true}
+	page, err := utils.CursorPaginate(db, p, keyset, func(l *sellerListing) uuid.UUID { return l.ID }, &listings)
+	if err != nil {
+		return nil, page, err
+	}
+	return listings, page, nil
+}
@@ -0,0 +1,100 @@
+package resale
+
+import (
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/passes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompleteSale hands the units a confirmed resale order bought from the
+// seller to the buyer: the passes move to the buyer's payment with new codes,
+// the seller's payment records them as resold, and the sale is recorded with
+// the seller's proceeds after the platform fee.
+func CompleteSale(tx *gorm.DB, order *entities.Order, payment *entities.Payment) error {
+	var listing entities.Listing
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.ListingId.UUID).First(&listing).Error; err != nil {
+		return err
+	}
+	if listing.Status != entities.ListingActive {
+		return ErrListingNotActive
+	}
+
+	var listed []entities.ListingPass
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("listing_id = ? AND NOT sold", listing.ID).
+		Order("created_at ASC").
+		Limit(order.Quantity).
+		Find(&listed).Error
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, len(listed))
+	for i, lp := range listed {
+		ids[i] = lp.PassId
+	}
+
+	var held int64
+	err = tx.Model(&entities.Pass{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ? AND user_id = ? AND status = ?", ids, listing.UserId, entities.PassValid).
+		Count(&held).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) < order.Quantity || int(held) != len(ids) {
+		return ErrListingUnavailable
+	}
+
+	if err := passes.MovePasses(tx, ids, payment.UserId, payment.ID); err != nil {
+		return err
+	}
+	if err := tx.Model(&entities.ListingPass{}).Where("listing_id = ? AND pass_id IN ?", listing.ID, ids).Update("sold", true).Error; err != nil {
+		return err
+	}
+
+	listing.ReservedQuantity -= order.Quantity
+	listing.SoldQuantity += order.Quantity
+	if listing.SoldQuantity == listing.Quantity {
+		listing.Status = entities.ListingSold
+	}
+	if err := tx.Model(&listing).Select("reserved_quantity", "sold_quantity", "status").Updates(&listing).Error; err != nil {
+		return err
+	}
+
+	err = tx.Model(&entities.Payment{}).Where("id = ?", listing.PaymentId).
+		Update("resold_quantity", gorm.Expr("resold_quantity + ?", order.Quantity)).Error
+	if err != nil {
+		return err
+	}
+
+	// the seller is paid out of the price, not the buyer's fees and tax
+	fee := payment.FaceAmount * FeePercent() / 100
+	sale := entities.ResaleSale{
+		ID:        uuid.New(),
+		ListingId: listing.ID,
+		PaymentId: payment.ID,
+		SellerId:  listing.UserId,
+		BuyerId:   payment.UserId,
+		Quantity:  order.Quantity,
+		Amount:    payment.FaceAmount,
+		Fee:       fee,
+		Proceeds:  payment.FaceAmount - fee,
+	}
+	return tx.Create(&sale).Error
+}
+
+// ReleaseHold hands the units a resale order held back to its listing.
+func ReleaseHold(tx *gorm.DB, order *entities.Order) error {
+	return tx.Model(&entities.Listing{}).Where("id = ?", order.ListingId.UUID).
+		Update("reserved_quantity", gorm.Expr("reserved_quantity - ?", order.Quantity)).Error
+}
+
+// CancelPaymentListings withdraws the active listings of a payment, e.g.
+// before refunding it. Orders still holding units of them fail to complete.
+func CancelPaymentListings(tx *gorm.DB, paymentId uuid.UUID) error {
+	return tx.Model(&entities.Listing{}).
+		Where("payment_id = ? AND status = ?", paymentId, entities.ListingActive).
+		Update("status", entities.ListingCanceled).Error
+}
@@ -0,0 +1,53 @@
+package resale
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultFeePercent = 10
+
+var (
+	ErrResaleDisabled     = errors.New("resale is disabled for this event")
+	ErrResaleClosed       = errors.New("resale closes when the event starts")
+	ErrEventNotOnSale     = errors.New("event is not on sale")
+	ErrPriceAboveCap      = errors.New("price exceeds the event's resale price cap")
+	ErrNotEnoughPasses    = errors.New("not enough resellable passes")
+	ErrNotPaymentHolder   = errors.New("payment belongs to another user")
+	ErrListingNotActive   = errors.New("listing is not active")
+	ErrListingReserved    = errors.New("listing has units held by buyers")
+	ErrOwnListing         = errors.New("cannot buy your own listing")
+	ErrNotEnoughAvailable = errors.New("not enough units available on this listing")
+	ErrListingUnavailable = errors.New("listed passes are no longer held by the seller")
+)
+
+type ResaleService struct {
+	db         *gorm.DB
+	logger     *slog.Logger
+	holdWindow time.Duration // how long a buyer has to pay, as for any order
+}
+
+func NewResaleService(db *gorm.DB, logger *slog.Logger, holdWindow time.Duration) *ResaleService {
+	return &ResaleService{db: db, logger: logger, holdWindow: holdWindow}
+}
+
+// FeePercent is the platform's cut of each resale. RESALE_FEE_PERCENT
+// overrides the default.
+func FeePercent() int64 {
+	if value := os.Getenv("RESALE_FEE_PERCENT"); value != "" {
+		if percent, err := strconv.ParseInt(value, 10, 64); err == nil && percent >= 0 && percent <= 100 {
+			return percent
+		}
+	}
+	return defaultFeePercent
+}
+
+// PriceCap is the highest unit price a listing may ask for a ticket.
+func PriceCap(facePrice int64, maxMarkup int) int64 {
+	return facePrice * int64(100+maxMarkup) / 100
+}
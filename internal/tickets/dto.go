@@ -1,38 +1,133 @@
 package tickets
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/currency"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
 )
 
 type TicketCreateDTO struct {
-	Price           int64 `json:"price" binding:"required"`
-	TotalQuantities int   `json:"total_quantities" binding:"required"`
+	Name            string     `json:"name" binding:"required"`
+	Description     *string    `json:"description"`
+	Price           int64      `json:"price" binding:"required"`
+	Currency        *string    `json:"currency"` // defaults to the event's
+	TotalQuantities int        `json:"total_quantities" binding:"required"`
+	SalesStart      *time.Time `json:"sales_start"`
+	SalesEnd        *time.Time `json:"sales_end"`
+	MinPerOrder     *int       `json:"min_per_order"`
+	MaxPerOrder     *int       `json:"max_per_order"`
+	MaxPerUser      *int       `json:"max_per_user"`
+	Visibility      *string    `json:"visibility"`
+	// TransfersEnabled defaults to true
+	TransfersEnabled *bool `json:"transfers_enabled"`
 }
 
 type Ticket struct {
-	ID                  uuid.UUID `json:"id"`
-	EventId             uuid.UUID `json:"event_id"`
-	Price               int64     `json:"price"`
-	TotalQuantities     int       `json:"total_quantities"`
-	RemainingQuantities int       `json:"remaining_quantities"`
+	ID                  uuid.UUID  `json:"id"`
+	EventId             uuid.UUID  `json:"event_id"`
+	Name                string     `json:"name"`
+	Description         string     `json:"description,omitempty"`
+	Price               int64      `json:"price"`
+	Currency            string     `json:"currency"`
+	TotalQuantities     int        `json:"total_quantities"`
+	RemainingQuantities int        `json:"remaining_quantities"`
+	SalesStart          *time.Time `json:"sales_start,omitempty"`
+	SalesEnd            *time.Time `json:"sales_end,omitempty"`
+	MinPerOrder         int        `json:"min_per_order"`
+	MaxPerOrder         int        `json:"max_per_order,omitempty"`
+	MaxPerUser          int        `json:"max_per_user,omitempty"`
+	Visibility          string     `json:"visibility"`
+	TransfersEnabled    bool       `json:"transfers_enabled"`
+	OnSale              bool       `json:"on_sale"`
 }
 
 func TicketEntityToTicket(t *entities.Ticket) Ticket {
-	return Ticket{
+	ticket := Ticket{
 		ID:                  t.ID,
 		EventId:             t.EventId,
+		Name:                t.Name,
+		Description:         t.Description.String,
 		Price:               t.Price,
+		Currency:            t.Currency,
 		TotalQuantities:     t.TotalQuantities,
 		RemainingQuantities: t.RemainingQuantities,
+		MinPerOrder:         t.MinPerOrder,
+		MaxPerOrder:         t.MaxPerOrder,
+		MaxPerUser:          t.MaxPerUser,
+		Visibility:          t.Visibility,
+		TransfersEnabled:    t.TransfersEnabled,
+		OnSale:              t.OnSale(time.Now()),
+	}
+	if t.SalesStart.Valid {
+		ticket.SalesStart = &t.SalesStart.Time
+	}
+	if t.SalesEnd.Valid {
+		ticket.SalesEnd = &t.SalesEnd.Time
+	}
+	return ticket
+}
+
+func TicketEntitiesToTickets(tickets []entities.Ticket) []Ticket {
+	result := make([]Ticket, len(tickets))
+	for i := range tickets {
+		result[i] = TicketEntityToTicket(&tickets[i])
 	}
+	return result
 }
 
 func (t *TicketCreateDTO) Validate() utils.ValidationErrors {
 	v := utils.NewValidator()
+	v.Must(len(t.Name) >= 2 && len(t.Name) <= 255, "name", "name must be between 2 and 255 characters")
+	if t.Description != nil {
+		v.Must(len(*t.Description) >= 2 && len(*t.Description) <= 1024, "description", "description must be between 2 and 1024 characters")
+	}
 	v.Must(t.Price > 0, "price", "must be positive integer")
+	if t.Currency != nil {
+		v.Must(currency.Valid(*t.Currency), "currency", "currency must be a supported ISO 4217 code")
+	}
 	v.Must(t.TotalQuantities > 0, "total_quantities", "must be positive integer")
+	if t.SalesStart != nil && t.SalesEnd != nil {
+		v.Must(t.SalesEnd.After(*t.SalesStart), "sales_end", "sales_end should be after sales_start")
+	}
+	if t.SalesEnd != nil {
+		v.Must(t.SalesEnd.After(time.Now()), "sales_end", "sales_end should be in future")
+	}
+	minPerOrder := 1
+	if t.MinPerOrder != nil {
+		minPerOrder = *t.MinPerOrder
+		v.Must(minPerOrder > 0, "min_per_order", "must be positive integer")
+		v.Must(minPerOrder <= t.TotalQuantities, "min_per_order", "must not exceed total_quantities")
+	}
+	if t.MaxPerOrder != nil {
+		v.Must(*t.MaxPerOrder >= minPerOrder, "max_per_order", "must be at least min_per_order")
+	}
+	if t.MaxPerUser != nil {
+		v.Must(*t.MaxPerUser >= 0, "max_per_user", "must not be negative")
+	}
+	if t.Visibility != nil {
+		v.In(*t.Visibility, entities.TicketVisibilities, "visibility", "visibility must be one of public, hidden")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+// TicketUpdateDTO changes a ticket after creation. TotalQuantities may not go
+// below the units already sold or held.
+type TicketUpdateDTO struct {
+	TotalQuantities  *int  `json:"total_quantities"`
+	TransfersEnabled *bool `json:"transfers_enabled"`
+}
+
+func (t *TicketUpdateDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	if t.TotalQuantities != nil {
+		v.Must(*t.TotalQuantities > 0, "total_quantities", "must be positive integer")
+	}
 	if !v.Valid() {
 		return v.Errors
 	}
@@ -1,6 +1,7 @@
 package tickets
 
 import (
+	"database/sql"
 	"errors"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
 	"gorm.io/gorm"
 )
 
@@ -56,9 +58,41 @@ func (service *TicketsService) CreateTicketHandler(c *gin.Context) {
 	ticket := entities.Ticket{
 		EventId:             eventId,
 		UserId:              userId,
+		Name:                input.Name,
 		Price:               input.Price,
+		Currency:            event.Currency,
 		TotalQuantities:     input.TotalQuantities,
 		RemainingQuantities: input.TotalQuantities,
+		MinPerOrder:         1,
+		Visibility:          entities.TicketPublic,
+		TransfersEnabled:    true,
+	}
+	if input.Description != nil {
+		ticket.Description = sql.NullString{String: *input.Description, Valid: true}
+	}
+	if input.Currency != nil {
+		ticket.Currency = utils.NormalizeCode(*input.Currency)
+	}
+	if input.SalesStart != nil {
+		ticket.SalesStart = sql.NullTime{Time: *input.SalesStart, Valid: true}
+	}
+	if input.SalesEnd != nil {
+		ticket.SalesEnd = sql.NullTime{Time: *input.SalesEnd, Valid: true}
+	}
+	if input.MinPerOrder != nil {
+		ticket.MinPerOrder = *input.MinPerOrder
+	}
+	if input.MaxPerOrder != nil {
+		ticket.MaxPerOrder = *input.MaxPerOrder
+	}
+	if input.MaxPerUser != nil {
+		ticket.MaxPerUser = *input.MaxPerUser
+	}
+	if input.Visibility != nil {
+		ticket.Visibility = *input.Visibility
+	}
+	if input.TransfersEnabled != nil {
+		ticket.TransfersEnabled = *input.TransfersEnabled
 	}
 
 	if err := service.createTicket(c.Request.Context(), &ticket); err != nil {
@@ -66,6 +100,10 @@ func (service *TicketsService) CreateTicketHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event or user ID"})
 			return
 		}
+		if errors.Is(err, venues.ErrOverCapacity) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		service.logger.Error("failed to create ticket", "error", err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
@@ -81,6 +119,11 @@ func (service *TicketsService) GetTicket(c *gin.Context) {
 		return
 	}
 	ticket, err := service.getTicket(c.Request.Context(), ticketId)
+	if err == nil {
+		userIdAny, _ := c.Get("user_id")
+		userId, _ := userIdAny.(uuid.UUID)
+		err = service.canSee(ticket, userId, c.GetString("user_role"))
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
@@ -93,6 +136,39 @@ func (service *TicketsService) GetTicket(c *gin.Context) {
 	c.JSON(http.StatusOK, TicketEntityToTicket(ticket))
 }
 
+func (service *TicketsService) UpdateTicketHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+
+	var input TicketUpdateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	ticket, err := service.updateTicket(c.Request.Context(), ticketId, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		case errors.Is(err, ErrBelowSold), errors.Is(err, venues.ErrOverCapacity):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed to update ticket", "ticketId", ticketId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, TicketEntityToTicket(ticket))
+}
+
 func (service *TicketsService) DeleteTicket(c *gin.Context) {
 	id := c.Param("id")
 	ticketId, err := uuid.Parse(id)
@@ -113,7 +189,7 @@ func (service *TicketsService) DeleteTicket(c *gin.Context) {
 }
 
 func (service *TicketsService) GetEventTicketsHandler(c *gin.Context) {
-	var p utils.Pagination
+	var p utils.CursorPagination
 	if err := c.ShouldBindQuery(&p); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
 		return
@@ -126,18 +202,28 @@ func (service *TicketsService) GetEventTicketsHandler(c *gin.Context) {
 		return
 	}
 
-	tickets, total, err := service.getEventTickets(c.Request.Context(), eventId, &p)
+	userIdAny, _ := c.Get("user_id")
+	userId, _ := userIdAny.(uuid.UUID)
+	tickets, page, err := service.getEventTickets(c.Request.Context(), eventId, userId, c.GetString("user_role"), c.Query("access_code"), &p)
 	if err != nil {
-		service.logger.Error("failed to get users", "page", p.Page, "limit", p.PageSize, "error", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get tickets", "limit", p.PageSize, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":      tickets,
-		"total":     total,
-		"page":      p.Page,
-		"page_size": p.PageSize,
+		"data":        TicketEntitiesToTickets(tickets),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
 	})
 }
 
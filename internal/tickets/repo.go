@@ -2,13 +2,20 @@ package tickets
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/accesscodes"
 	"github.com/rezbow/tickr/internal/entities"
 	"github.com/rezbow/tickr/internal/utils"
+	"github.com/rezbow/tickr/internal/venues"
+	"github.com/rezbow/tickr/internal/waitlist"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrBelowSold = errors.New("total_quantities is below the units already sold or held")
+
 func (svc *TicketsService) getEvent(eventId uuid.UUID) (*entities.Event, error) {
 	var event entities.Event
 	if err := svc.db.Where("id = ?", eventId).First(&event).Error; err != nil {
@@ -17,13 +24,35 @@ func (svc *TicketsService) getEvent(eventId uuid.UUID) (*entities.Event, error)
 	return &event, nil
 }
 
+// createTicket adds a ticket to its event, as long as the event's tickets
+// still fit its venue.
 func (service *TicketsService) createTicket(ctx context.Context, ticket *entities.Ticket) error {
 	ticket.ID = uuid.New()
-	err := gorm.G[entities.Ticket](service.db).Create(ctx, ticket)
+	return service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkVenueCapacity(tx, ticket.EventId, ticket.ID, ticket.TotalQuantities); err != nil {
+			return err
+		}
+		return tx.Create(ticket).Error
+	})
+}
+
+// checkVenueCapacity locks the event, so its ticket totals hold until the
+// transaction ends, and checks them against the venue with ticketId at
+// quantity. A missing event is left to the foreign key.
+func checkVenueCapacity(tx *gorm.DB, eventId, ticketId uuid.UUID, quantity int) error {
+	var event entities.Event
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", eventId).Limit(1).Find(&event)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	if !event.VenueId.Valid {
+		return nil
+	}
+	total, err := venues.TicketTotal(tx, eventId, ticketId)
 	if err != nil {
 		return err
 	}
-	return nil
+	return venues.CheckCapacity(tx, &event, total+quantity)
 }
 
 func (service *TicketsService) getTicket(ctx context.Context, id uuid.UUID) (*entities.Ticket, error) {
@@ -35,6 +64,63 @@ func (service *TicketsService) getTicket(ctx context.Context, id uuid.UUID) (*en
 	return &ticket, nil
 }
 
+// canSee returns gorm.ErrRecordNotFound unless the ticket's event is visible
+// to userId holding role and, for a hidden ticket, userId owns the event or
+// is an admin.
+func (service *TicketsService) canSee(ticket *entities.Ticket, userId uuid.UUID, role string) error {
+	event, err := service.getEvent(ticket.EventId)
+	if err != nil {
+		return err
+	}
+	if !event.VisibleTo(userId, role) {
+		return gorm.ErrRecordNotFound
+	}
+	// hidden tiers are only known to their organizer until a code is handed out
+	if ticket.Visibility == entities.TicketHidden && event.UserId != userId && role != "admin" {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// updateTicket applies input to a ticket. Units added to TotalQuantities are
+// offered to the ticket's waitlist first.
+func (service *TicketsService) updateTicket(ctx context.Context, id uuid.UUID, input TicketUpdateDTO) (*entities.Ticket, error) {
+	var ticket entities.Ticket
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&ticket).Error; err != nil {
+			return err
+		}
+
+		if input.TotalQuantities != nil {
+			if *input.TotalQuantities > ticket.TotalQuantities {
+				if err := checkVenueCapacity(tx, ticket.EventId, ticket.ID, *input.TotalQuantities); err != nil {
+					return err
+				}
+			}
+			taken := ticket.TotalQuantities - ticket.RemainingQuantities
+			if *input.TotalQuantities < taken {
+				return ErrBelowSold
+			}
+			added := *input.TotalQuantities > ticket.TotalQuantities
+			ticket.TotalQuantities = *input.TotalQuantities
+			ticket.RemainingQuantities = *input.TotalQuantities - taken
+			if added {
+				if err := waitlist.OfferReleased(tx, &ticket); err != nil {
+					return err
+				}
+			}
+		}
+		if input.TransfersEnabled != nil {
+			ticket.TransfersEnabled = *input.TransfersEnabled
+		}
+		return tx.Save(&ticket).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
 func (service *TicketsService) deleteTicket(ctx context.Context, id uuid.UUID) error {
 	rowsAffected, err := gorm.G[entities.Ticket](service.db).Where("id = ?", id).Delete(ctx)
 	if rowsAffected == 0 {
@@ -45,15 +131,28 @@ func (service *TicketsService) deleteTicket(ctx context.Context, id uuid.UUID) e
 	return nil
 }
 
-func (service *TicketsService) getEventTickets(ctx context.Context, eventId uuid.UUID, p *utils.Pagination) ([]entities.Ticket, int64, error) {
-	var total int64
-	if res := service.db.Model(&entities.Ticket{}).Where("event_id = ?", eventId).Count(&total); res.Error != nil {
-		return nil, 0, res.Error
+// getEventTickets lists the public tickets of an event, cheapest first, along
+// with the hidden ones accessCode unlocks when given. The tickets of a draft
+// are only listed to whom the draft is visible.
+func (service *TicketsService) getEventTickets(ctx context.Context, eventId, userId uuid.UUID, role, accessCode string, p *utils.CursorPagination) ([]entities.Ticket, utils.Page, error) {
+	event, err := service.getEvent(eventId)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	if !event.VisibleTo(userId, role) {
+		return nil, utils.Page{}, gorm.ErrRecordNotFound
 	}
+
+	visible := service.db.Where("visibility = ?", entities.TicketPublic)
+	if accessCode != "" {
+		visible = visible.Or("id IN (?)", accesscodes.Unlocked(service.db, accessCode))
+	}
+
 	var tickets []entities.Ticket
-	err := service.db.Scopes(p.Paginate).Where("event_id = ?", eventId).Find(&tickets).Error
+	keyset := utils.Keyset{Name: "price", Table: "tickets", Column: "tickets.price"}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Where("event_id = ?", eventId).Where(visible), p, keyset, func(t *entities.Ticket) uuid.UUID { return t.ID }, &tickets)
 	if err != nil {
-		return nil, 0, err
+		return nil, page, err
 	}
-	return tickets, total, nil
+	return tickets, page, nil
 }
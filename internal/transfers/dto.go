@@ -0,0 +1,101 @@
+package transfers
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+type TransferCreateDTO struct {
+	TicketId uuid.UUID `json:"ticket_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required"`
+	Email    string    `json:"email" binding:"required"`
+}
+
+func (t *TransferCreateDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(t.Quantity > 0, "quantity", "must be positive integer")
+	v.Must(len(t.Email) > 2 && len(t.Email) < 255, "email", "email must be between 2 and 255 characters")
+	v.Regex(t.Email, emailPattern, "email", "invalid email format")
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+// TransferAcceptDTO accepts a transfer. Name and Password are only needed
+// when the recipient has no account yet.
+type TransferAcceptDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (t *TransferAcceptDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(len(t.Token) > 0 && len(t.Token) <= 128, "token", "invalid token")
+	if t.Name != "" {
+		v.Must(len(t.Name) > 2 && len(t.Name) < 255, "name", "name must be between 2 and 255 characters")
+	}
+	if t.Password != "" {
+		v.Must(len(t.Password) >= 8, "password", "password must be at least 8 characters")
+	}
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type Transfer struct {
+	ID             uuid.UUID   `json:"id"`
+	UserId         uuid.UUID   `json:"user_id"`
+	RecipientId    *uuid.UUID  `json:"recipient_id,omitempty"`
+	RecipientEmail string      `json:"recipient_email"`
+	TicketId       uuid.UUID   `json:"ticket_id"`
+	Quantity       int         `json:"quantity"`
+	Status         string      `json:"status"`
+	PassIds        []uuid.UUID `json:"pass_ids"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+	AcceptedAt     *time.Time  `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func TransferEntityToTransfer(t *entities.Transfer) Transfer {
+	transfer := Transfer{
+		ID:             t.ID,
+		UserId:         t.UserId,
+		RecipientEmail: t.RecipientEmail,
+		TicketId:       t.TicketId,
+		Quantity:       t.Quantity,
+		Status:         t.Status,
+		PassIds:        make([]uuid.UUID, len(t.Passes)),
+		ExpiresAt:      t.ExpiresAt,
+		CreatedAt:      t.CreatedAt,
+	}
+	if t.Expired(time.Now()) {
+		transfer.Status = "expired"
+	}
+	if t.RecipientId.Valid {
+		transfer.RecipientId = &t.RecipientId.UUID
+	}
+	if t.AcceptedAt.Valid {
+		transfer.AcceptedAt = &t.AcceptedAt.Time
+	}
+	for i, p := range t.Passes {
+		transfer.PassIds[i] = p.PassId
+	}
+	return transfer
+}
+
+func TransferEntitiesToTransfers(transfers []entities.Transfer) []Transfer {
+	result := make([]Transfer, len(transfers))
+	for i := range transfers {
+		result[i] = TransferEntityToTransfer(&transfers[i])
+	}
+	return result
+}
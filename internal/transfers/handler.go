@@ -0,0 +1,142 @@
+package transfers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (service *TransfersService) CreateTransferHandler(c *gin.Context) {
+	var input TransferCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	transfer, err := service.createTransfer(c.Request.Context(), userId, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		case errors.Is(err, ErrSelfTransfer), errors.Is(err, ErrNotEnoughPasses):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTransfersDisabled), errors.Is(err, ErrTransferClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed creating transfer", "userId", userId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, TransferEntityToTransfer(transfer))
+}
+
+func (service *TransfersService) AcceptTransferHandler(c *gin.Context) {
+	var input TransferAcceptDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	transfer, created, err := service.acceptTransfer(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		case errors.Is(err, ErrAccountRequired):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTransferExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTransferNotPending), errors.Is(err, ErrTransferClosed),
+			errors.Is(err, ErrTransferUnavailable), errors.Is(err, ErrSelfTransfer):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			c.JSON(http.StatusConflict, gin.H{"error": "an account with this email was just created, please retry"})
+		default:
+			service.logger.Error("failed accepting transfer", "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transfer":        TransferEntityToTransfer(transfer),
+		"account_created": created,
+	})
+}
+
+func (service *TransfersService) CancelTransferHandler(c *gin.Context) {
+	transferId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		return
+	}
+
+	transfer, err := service.cancelTransfer(c.Request.Context(), transferId)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		case errors.Is(err, ErrTransferNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed canceling transfer", "transferId", transferId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, TransferEntityToTransfer(transfer))
+}
+
+func (service *TransfersService) GetMyTransfersHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	transfers, page, err := service.getUserTransfers(c.Request.Context(), userId, c.GetString("user_email"), &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get transfers", "userId", userId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        TransferEntitiesToTransfers(transfers),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
@@ -0,0 +1,321 @@
+package transfers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/mail"
+	"github.com/rezbow/tickr/internal/passes"
+	"github.com/rezbow/tickr/internal/users"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// createTransfer sets aside input.Quantity of the sender's valid passes for
+// input.Ticket and offers them to input.Email, mailing the token accepting
+// them there. Only the recipient ever sees the token, so only someone who
+// reads that mailbox can accept, or sign up with that address doing so. The
+// sender's row is locked so concurrent transfers cannot set aside the same
+// passes.
+func (service *TransfersService) createTransfer(ctx context.Context, senderId uuid.UUID, input TransferCreateDTO) (*entities.Transfer, error) {
+	var transfer *entities.Transfer
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sender entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "email").Where("id = ?", senderId).First(&sender).Error; err != nil {
+			return err
+		}
+		if strings.EqualFold(sender.Email, input.Email) {
+			return ErrSelfTransfer
+		}
+
+		var ticket entities.Ticket
+		if err := tx.Preload("Event").Where("id = ?", input.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if !ticket.TransfersEnabled {
+			return ErrTransfersDisabled
+		}
+		now := time.Now()
+		if !now.Before(ticket.Event.StartTime) {
+			return ErrTransferClosed
+		}
+
+		var ids []uuid.UUID
+		err := tx.Model(&entities.Pass{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND ticket_id = ? AND status = ?", senderId, ticket.ID, entities.PassValid).
+			Scopes(passes.Unencumbered(now)).
+			Order("created_at ASC").
+			Limit(input.Quantity).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) < input.Quantity {
+			return ErrNotEnoughPasses
+		}
+
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+		transfer = &entities.Transfer{
+			ID:             uuid.New(),
+			UserId:         senderId,
+			RecipientEmail: input.Email,
+			TicketId:       ticket.ID,
+			Quantity:       input.Quantity,
+			Status:         entities.TransferPending,
+			TokenHash:      hashToken(token),
+			ExpiresAt:      now.Add(acceptWindow),
+			Passes:         make([]entities.TransferPass, len(ids)),
+		}
+		for i, id := range ids {
+			transfer.Passes[i] = entities.TransferPass{ID: uuid.New(), TransferId: transfer.ID, PassId: id}
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		// sent last, so the transfer is only kept once its token is on its way
+		return service.mailer.Send(ctx, transferMessage(sender.Email, &ticket, transfer, token))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// transferMessage tells the recipient of transfer how to accept it.
+func transferMessage(senderEmail string, ticket *entities.Ticket, transfer *entities.Transfer, token string) mail.Message {
+	return mail.Message{
+		To:      transfer.RecipientEmail,
+		Subject: "Passes for " + ticket.Event.Title,
+		Body: fmt.Sprintf("%s sent you %d %s pass(es) for %s.\n\n"+
+			"Accept them before %s by posting this token to /transfers/accept:\n\n%s\n\n"+
+			"If you have no account yet, add a name and password to create one with this address.\n",
+			senderEmail, transfer.Quantity, ticket.Name, ticket.Event.Title,
+			transfer.ExpiresAt.UTC().Format(time.RFC1123), token),
+	}
+}
+
+// acceptTransfer moves the passes of the transfer identified by input.Token
+// to the account registered with its recipient email, creating that account
+// from input.Name and input.Password when there is none. Moved passes get new
+// codes and a payment of the recipient's, see handOver. It reports whether an
+// account was created.
+func (service *TransfersService) acceptTransfer(ctx context.Context, input TransferAcceptDTO) (*entities.Transfer, bool, error) {
+	var transfer entities.Transfer
+	created := false
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Passes").
+			Where("token_hash = ?", hashToken(input.Token)).First(&transfer).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if transfer.Status != entities.TransferPending {
+			return ErrTransferNotPending
+		}
+		if transfer.Expired(now) {
+			return ErrTransferExpired
+		}
+
+		var ticket entities.Ticket
+		if err := tx.Preload("Event").Where("id = ?", transfer.TicketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if !now.Before(ticket.Event.StartTime) {
+			return ErrTransferClosed
+		}
+
+		var recipient *entities.User
+		recipient, created, err = findOrCreateRecipient(tx, transfer.RecipientEmail, input)
+		if err != nil {
+			return err
+		}
+		if recipient.ID == transfer.UserId {
+			return ErrSelfTransfer
+		}
+
+		ids := make([]uuid.UUID, len(transfer.Passes))
+		for i, p := range transfer.Passes {
+			ids[i] = p.PassId
+		}
+		if err := handOver(tx, ids, transfer.UserId, recipient.ID); err != nil {
+			return err
+		}
+
+		transfer.Status = entities.TransferAccepted
+		transfer.RecipientId = uuid.NullUUID{UUID: recipient.ID, Valid: true}
+		transfer.AcceptedAt = sql.NullTime{Time: now, Valid: true}
+		return tx.Model(&transfer).Select("status", "recipient_id", "accepted_at").Updates(&transfer).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &transfer, created, nil
+}
+
+// handOver moves the sender's passes ids to the recipient the way a resale
+// does: for each payment the passes were bought with, the recipient gets a
+// payment of their own for their share of it, the passes move to that payment
+// with new codes, and the sender's payment records them as transferred. The
+// split payment keeps the charge it came from, so refunding it pays the
+// sender back.
+func handOver(tx *gorm.DB, ids []uuid.UUID, senderId, recipientId uuid.UUID) error {
+	var paymentIds []uuid.UUID
+	if err := tx.Model(&entities.Pass{}).Distinct("payment_id").Where("id IN ?", ids).Pluck("payment_id", &paymentIds).Error; err != nil {
+		return err
+	}
+	// payments are locked before their passes, as refunds do
+	var sources []entities.Payment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", paymentIds).Order("id").Find(&sources).Error
+	if err != nil {
+		return err
+	}
+
+	var held []entities.Pass
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "payment_id").
+		Where("id IN ? AND user_id = ? AND status = ?", ids, senderId, entities.PassValid).
+		Find(&held).Error
+	if err != nil {
+		return err
+	}
+	if len(held) != len(ids) {
+		return ErrTransferUnavailable
+	}
+	moved := make(map[uuid.UUID][]uuid.UUID, len(sources))
+	for _, pass := range held {
+		moved[pass.PaymentId] = append(moved[pass.PaymentId], pass.ID)
+	}
+
+	for i := range sources {
+		source := &sources[i]
+		passIds := moved[source.ID]
+		if len(passIds) == 0 {
+			continue
+		}
+		if source.UserId != senderId || source.RefundableQuantity() < len(passIds) {
+			return ErrTransferUnavailable
+		}
+		split := splitPayment(source, recipientId, len(passIds))
+		if err := tx.Create(split).Error; err != nil {
+			return err
+		}
+		if err := passes.MovePasses(tx, passIds, recipientId, split.ID); err != nil {
+			return err
+		}
+		err := tx.Model(&entities.Payment{}).Where("id = ?", source.ID).
+			Update("transferred_quantity", gorm.Expr("transferred_quantity + ?", len(passIds))).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPayment is the recipient's payment for quantity units of source, with
+// the matching share of each of its amounts. It points at the payment that
+// was charged, which is source itself unless source was split off too.
+func splitPayment(source *entities.Payment, recipientId uuid.UUID, quantity int) *entities.Payment {
+	share := func(amount int64) int64 {
+		return amount * int64(quantity) / int64(source.Quantity)
+	}
+	charged := source.TransferredFrom
+	if !charged.Valid {
+		charged = uuid.NullUUID{UUID: source.ID, Valid: true}
+	}
+	return &entities.Payment{
+		ID:              uuid.New(),
+		UserId:          recipientId,
+		TicketId:        source.TicketId,
+		OrderId:         source.OrderId,
+		Quantity:        quantity,
+		Status:          entities.PaymentConfirmed,
+		Currency:        source.Currency,
+		PaidAmount:      share(source.PaidAmount),
+		FaceAmount:      share(source.FaceAmount),
+		DiscountAmount:  share(source.DiscountAmount),
+		FeeAmount:       share(source.FeeAmount),
+		FeeAbsorbed:     source.FeeAbsorbed,
+		TaxAmount:       share(source.TaxAmount),
+		TransferredFrom: charged,
+		Provider:        source.Provider,
+		ProviderRef:     source.ProviderRef,
+	}
+}
+
+// findOrCreateRecipient returns the user registered with email, signing one
+// up from input when there is none. It reports whether the user was created.
+func findOrCreateRecipient(tx *gorm.DB, email string, input TransferAcceptDTO) (*entities.User, bool, error) {
+	var user entities.User
+	err := tx.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	if err == nil {
+		return &user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if input.Name == "" || input.Password == "" {
+		return nil, false, ErrAccountRequired
+	}
+	signup := users.UserCreateDTO{
+		Name:     input.Name,
+		Email:    email,
+		Password: input.Password,
+		Role:     "user",
+	}
+	recipient, err := signup.ToEntity()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := users.CreateUser(tx, recipient); err != nil {
+		return nil, false, err
+	}
+	return recipient, true, nil
+}
+
+// cancelTransfer withdraws a pending transfer, releasing its passes.
+func (service *TransfersService) cancelTransfer(ctx context.Context, transferId uuid.UUID) (*entities.Transfer, error) {
+	var transfer entities.Transfer
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Passes").Where("id = ?", transferId).First(&transfer).Error
+		if err != nil {
+			return err
+		}
+		if transfer.Status != entities.TransferPending {
+			return ErrTransferNotPending
+		}
+		transfer.Status = entities.TransferCanceled
+		return tx.Model(&transfer).Update("status", entities.TransferCanceled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// getUserTransfers pages through transfers the user sent or received,
+// including pending ones addressed to their email, newest first.
+func (service *TransfersService) getUserTransfers(ctx context.Context, userId uuid.UUID, email string, p *utils.CursorPagination) ([]entities.Transfer, utils.Page, error) {
+	db := service.db.WithContext(ctx).Preload("Passes").
+		Where("user_id = ? OR recipient_id = ? OR (status = ? AND LOWER(recipient_email) = LOWER(?))",
+			userId, userId, entities.TransferPending, email)
+
+	var transfers []entities.Transfer
+	keyset := utils.Keyset{Name: "created_at", Table: "transfers", Column: "transfers.created_at", Desc: true}
+	page, err := utils.CursorPaginate(db, p, keyset, func(t *entities.Transfer) uuid.UUID { return t.ID }, &transfers)
+	if err != nil {
+		return nil, page, err
+	}
+	return transfers, page, nil
+}
@@ -0,0 +1,34 @@
+package transfers
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/rezbow/tickr/internal/mail"
+	"gorm.io/gorm"
+)
+
+// acceptWindow is how long a recipient has to accept a transfer.
+const acceptWindow = 7 * 24 * time.Hour
+
+var (
+	ErrTransfersDisabled   = errors.New("transfers are disabled for this ticket")
+	ErrTransferClosed      = errors.New("transfers close when the event starts")
+	ErrNotEnoughPasses     = errors.New("not enough transferable passes")
+	ErrSelfTransfer        = errors.New("cannot transfer passes to yourself")
+	ErrTransferNotPending  = errors.New("transfer is not pending")
+	ErrTransferExpired     = errors.New("transfer has expired")
+	ErrTransferUnavailable = errors.New("transferred passes are no longer held by the sender")
+	ErrAccountRequired     = errors.New("name and password are required to create an account")
+)
+
+type TransfersService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+	mailer mail.Sender // delivers accept tokens to recipients
+}
+
+func NewTransfersService(db *gorm.DB, logger *slog.Logger, mailer mail.Sender) *TransfersService {
+	return &TransfersService{db: db, logger: logger, mailer: mailer}
+}
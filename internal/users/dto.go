@@ -30,6 +30,20 @@ func (u *UserCreateDTO) Validate() utils.ValidationErrors {
 	return nil
 }
 
+// ToEntity builds the user described by u, hashing its password.
+func (u *UserCreateDTO) ToEntity() (*entities.User, error) {
+	hash, err := hashPassword(u.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.User{
+		Name:         u.Name,
+		Email:        u.Email,
+		Role:         u.Role,
+		PasswordHash: hash,
+	}, nil
+}
+
 type UserUpdateDTO struct {
 	Name     *string `json:"name"`
 	Email    *string `json:"email"`
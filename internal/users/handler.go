@@ -23,20 +23,13 @@ func (service *UsersService) CreateUserHandler(c *gin.Context) {
 		return
 	}
 
-	hash, err := hashPassword(userInput.Password)
+	user, err := userInput.ToEntity()
 	if err != nil {
 		service.logger.Error("failed to hash password", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	user := &entities.User{
-		Name:         userInput.Name,
-		Email:        userInput.Email,
-		Role:         userInput.Role,
-		PasswordHash: hash,
-	}
-
 	if err := service.createUser(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,24 +89,28 @@ func (service *UsersService) GetUserHandler(c *gin.Context) {
 
 // getUsers: get all users with pagination
 func (service *UsersService) GetUsersHandler(c *gin.Context) {
-	var p utils.Pagination
+	var p utils.CursorPagination
 
 	if err := c.ShouldBindQuery(&p); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
 		return
 	}
 
-	users, total, err := service.getUsers(c.Request.Context(), &p)
+	users, page, err := service.getUsers(c.Request.Context(), &p)
 	if err != nil {
-		service.logger.Error("failed to get users", "page", p.Page, "page_size", p.PageSize, "error", err)
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get users", "page_size", p.PageSize, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"data":      UserEntitiesToUserResponse(users),
-		"total":     total,
-		"page":      p.Page,
-		"page_size": p.PageSize,
+		"data":        UserEntitiesToUserResponse(users),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
 	})
 }
 
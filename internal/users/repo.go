@@ -11,12 +11,14 @@ import (
 )
 
 func (service *UsersService) createUser(ctx context.Context, user *entities.User) error {
+	return CreateUser(service.db.WithContext(ctx), user)
+}
+
+// CreateUser inserts user using tx, so flows creating an account on someone's
+// behalf, such as accepting a ticket transfer, can do it in their transaction.
+func CreateUser(tx *gorm.DB, user *entities.User) error {
 	user.ID = uuid.New()
-	err := gorm.G[entities.User](service.db).Create(ctx, user)
-	if err != nil {
-		return err
-	}
-	return nil
+	return tx.Create(user).Error
 }
 
 // deleteUser deletes a user from the database.
@@ -39,48 +41,15 @@ func (service *UsersService) getUser(ctx context.Context, userID uuid.UUID) (*en
 	return &user, nil
 }
 
-// getUsers: retrieves a list of users from the database.
-func (service *UsersService) getUsers(ctx context.Context, p *utils.Pagination) ([]entities.User, int64, error) {
-	var total int64
-	if res := service.db.Model(&entities.User{}).Count(&total); res.Error != nil {
-		return nil, 0, res.Error
-	}
+// getUsers: retrieves a page of users from the database, oldest first.
+func (service *UsersService) getUsers(ctx context.Context, p *utils.CursorPagination) ([]entities.User, utils.Page, error) {
 	var users []entities.User
-	err := service.db.Scopes(p.Paginate).Find(&users).Error
+	keyset := utils.Keyset{Name: "created_at", Table: "users", Column: "users.created_at"}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx), p, keyset, func(u *entities.User) uuid.UUID { return u.ID }, &users)
 	if err != nil {
-		return nil, 0, err
-	}
-	return users, total, nil
-}
-
-// getUsersCursorPagination: retrieves a list of users with cursor pagination
-func (service *UsersService) getUsersCursorPagination(ctx context.Context, cursor string, limit int) ([]entities.User, string, error) {
-	var users []entities.User
-	var nextCursor string
-
-	if cursor != "" {
-		cursorID, err := uuid.Parse(cursor)
-		if err != nil {
-			return nil, "", err
-		}
-		users, err = gorm.G[entities.User](service.db).Where("id > ?", cursorID).Limit(limit).Order("id ASC").Find(ctx)
-		if err != nil {
-			return nil, "", err
-		}
-		if len(users) > 0 {
-			nextCursor = users[len(users)-1].ID.String()
-		}
-	} else {
-		users, err := gorm.G[entities.User](service.db).Limit(limit).Find(ctx)
-		if err != nil {
-			return nil, "", err
-		}
-		if len(users) > 0 {
-			nextCursor = users[len(users)-1].ID.String()
-		}
+		return nil, page, err
 	}
-
-	return users, nextCursor, nil
+	return users, page, nil
 }
 
 // updateUser updates a user in the database.
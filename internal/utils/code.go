@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// NormalizeCode is how codes matched regardless of case, such as discount
+// codes or ISO currency and country codes, are stored and compared.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
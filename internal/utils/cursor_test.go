@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("CURSOR_SIGNING_SECRET", "test-cursor-secret")
+	os.Exit(m.Run())
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	tests := []struct {
+		name string
+		key  any
+		kind string
+		want any
+	}{
+		{name: "time", key: at, kind: "time", want: at},
+		{name: "time in another zone", key: at.In(time.FixedZone("UTC+3", 3*3600)), kind: "time", want: at},
+		{name: "int", key: int64(-42), kind: "int", want: int64(-42)},
+		{name: "float", key: 0.1 + 0.2, kind: "float", want: 0.1 + 0.2},
+		{name: "string", key: "Jazz at noon, part 2.", kind: "string", want: "Jazz at noon, part 2."},
+		{name: "bytes", key: []byte("rock"), kind: "string", want: "rock"},
+		{name: "empty string", key: "", kind: "string", want: ""},
+	}
+	for _, tt := range tests {
+		for _, back := range []bool{false, true} {
+			t.Run(tt.name, func(t *testing.T) {
+				id := uuid.New()
+				cursor, err := encodeCursor("starts_at", tt.key, id, back)
+				if err != nil {
+					t.Fatalf("encodeCursor() error = %v", err)
+				}
+				pos, err := decodeCursor(cursor)
+				if err != nil {
+					t.Fatalf("decodeCursor() error = %v", err)
+				}
+				if pos.Order != "starts_at" || pos.Id != id || pos.Back != back || pos.Kind != tt.kind {
+					t.Errorf("decodeCursor() = %+v, want order starts_at, id %v, back %v, kind %s", pos, id, back, tt.kind)
+				}
+				got, err := pos.value()
+				if err != nil {
+					t.Fatalf("value() error = %v", err)
+				}
+				if when, ok := got.(time.Time); ok {
+					if !when.Equal(tt.want.(time.Time)) {
+						t.Errorf("value() = %v, want %v", when, tt.want)
+					}
+				} else if got != tt.want {
+					t.Errorf("value() = %#v, want %#v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeCursorRejectsUnsupportedKey(t *testing.T) {
+	if _, err := encodeCursor("starts_at", int32(1), uuid.New(), false); err == nil {
+		t.Error("encodeCursor() error = nil, want an error")
+	}
+}
+
+func TestCursorRequiresSecret(t *testing.T) {
+	t.Setenv("CURSOR_SIGNING_SECRET", "")
+	if _, err := encodeCursor("price", int64(2500), uuid.New(), false); !errors.Is(err, ErrMissingCursorSecret) {
+		t.Errorf("encodeCursor() error = %v, want %v", err, ErrMissingCursorSecret)
+	}
+	if _, err := decodeCursor("e30.c2lnbmF0dXJl"); !errors.Is(err, ErrMissingCursorSecret) {
+		t.Errorf("decodeCursor() error = %v, want %v", err, ErrMissingCursorSecret)
+	}
+}
+
+func TestDecodeCursorRejectsInvalid(t *testing.T) {
+	valid, err := encodeCursor("price", int64(2500), uuid.New(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, signature, _ := strings.Cut(valid, ".")
+	forged, err := encodeCursor("price", int64(1), uuid.New(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedBody, _, _ := strings.Cut(forged, ".")
+	secret, err := CursorSigningSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "no signature", cursor: body},
+		{name: "empty signature", cursor: body + "."},
+		{name: "wrong signature", cursor: body + "." + signature[1:] + "A"},
+		{name: "swapped body", cursor: forgedBody + "." + signature},
+		{name: "not base64", cursor: "!!!." + signCursor(secret, "!!!")},
+		{name: "not json", cursor: "bm90LWpzb24." + signCursor(secret, "bm90LWpzb24")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestPositionValueRejectsMalformedKey(t *testing.T) {
+	tests := []position{
+		{Kind: "time", Key: "yesterday"},
+		{Kind: "int", Key: "1.5"},
+		{Kind: "float", Key: "cheap"},
+		{Kind: "uuid", Key: uuid.NewString()},
+	}
+	for _, pos := range tests {
+		t.Run(pos.Kind, func(t *testing.T) {
+			if _, err := pos.value(); err == nil {
+				t.Errorf("value() of %+v error = nil, want an error", pos)
+			}
+		})
+	}
+}
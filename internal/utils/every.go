@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls fn every interval until ctx is canceled.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package utils
+
+import "strings"
+
+// EscapeLike escapes the LIKE wildcards in s.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
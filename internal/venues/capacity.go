@@ -0,0 +1,35 @@
+package venues
+
+import (
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"gorm.io/gorm"
+)
+
+// TicketTotal sums the total quantities of an event's tickets, leaving out
+// excludeTicketId, which may be uuid.Nil.
+func TicketTotal(tx *gorm.DB, eventId, excludeTicketId uuid.UUID) (int, error) {
+	var total int
+	err := tx.Model(&entities.Ticket{}).
+		Select("COALESCE(SUM(total_quantities), 0)").
+		Where("event_id = ? AND id <> ?", eventId, excludeTicketId).
+		Scan(&total).Error
+	return total, err
+}
+
+// CheckCapacity returns ErrOverCapacity when total units of tickets, or the
+// event's own capacity, don't fit the event's venue. Events without a venue
+// have no limit.
+func CheckCapacity(tx *gorm.DB, event *entities.Event, total int) error {
+	if !event.VenueId.Valid {
+		return nil
+	}
+	var venue entities.Venue
+	if err := tx.Select("id", "max_capacity").Where("id = ?", event.VenueId.UUID).First(&venue).Error; err != nil {
+		return err
+	}
+	if total > venue.MaxCapacity || event.Capacity > venue.MaxCapacity {
+		return ErrOverCapacity
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package venues
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type VenueCreateDTO struct {
+	Name         string  `json:"name" binding:"required"`
+	AddressLine1 string  `json:"address_line1" binding:"required"`
+	AddressLine2 *string `json:"address_line2"`
+	City         string  `json:"city" binding:"required"`
+	Region       *string `json:"region"`
+	PostalCode   *string `json:"postal_code"`
+	// Country is an ISO 3166-1 alpha-2 code
+	Country   string   `json:"country" binding:"required"`
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	// Timezone is an IANA name, such as Europe/Berlin
+	Timezone    string `json:"timezone" binding:"required"`
+	MaxCapacity int    `json:"max_capacity" binding:"required"`
+}
+
+func (v *VenueCreateDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	validator.Must(len(v.Name) >= 2 && len(v.Name) <= 255, "name", "name must be between 2 and 255 characters")
+	validator.Must(len(v.AddressLine1) >= 2 && len(v.AddressLine1) <= 255, "address_line1", "address_line1 must be between 2 and 255 characters")
+	if v.AddressLine2 != nil {
+		validator.Must(len(*v.AddressLine2) <= 255, "address_line2", "address_line2 must be at most 255 characters")
+	}
+	validator.Must(len(v.City) >= 1 && len(v.City) <= 255, "city", "city must be between 1 and 255 characters")
+	if v.Region != nil {
+		validator.Must(len(*v.Region) <= 255, "region", "region must be at most 255 characters")
+	}
+	if v.PostalCode != nil {
+		validator.Must(len(*v.PostalCode) <= 32, "postal_code", "postal_code must be at most 32 characters")
+	}
+	validator.Regex(utils.NormalizeCode(v.Country), countryPattern, "country", "country must be an ISO 3166-1 alpha-2 code")
+	if v.Latitude != nil {
+		validator.Must(*v.Latitude >= -90 && *v.Latitude <= 90, "latitude", "latitude must be between -90 and 90")
+	}
+	if v.Longitude != nil {
+		validator.Must(*v.Longitude >= -180 && *v.Longitude <= 180, "longitude", "longitude must be between -180 and 180")
+	}
+	validator.Must(validTimezone(v.Timezone), "timezone", "timezone must be an IANA time zone name")
+	validator.Must(v.MaxCapacity > 0, "max_capacity", "max_capacity must be positive integer")
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+// VenueUpdateDTO changes the fields it sets. MaxCapacity may not go below the
+// ticket quantities of events at the venue.
+type VenueUpdateDTO struct {
+	Name         *string  `json:"name"`
+	AddressLine1 *string  `json:"address_line1"`
+	AddressLine2 *string  `json:"address_line2"`
+	City         *string  `json:"city"`
+	Region       *string  `json:"region"`
+	PostalCode   *string  `json:"postal_code"`
+	Country      *string  `json:"country"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	Timezone     *string  `json:"timezone"`
+	MaxCapacity  *int     `json:"max_capacity"`
+}
+
+func (v *VenueUpdateDTO) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	if v.Name != nil {
+		validator.Must(len(*v.Name) >= 2 && len(*v.Name) <= 255, "name", "name must be between 2 and 255 characters")
+	}
+	if v.AddressLine1 != nil {
+		validator.Must(len(*v.AddressLine1) >= 2 && len(*v.AddressLine1) <= 255, "address_line1", "address_line1 must be between 2 and 255 characters")
+	}
+	if v.AddressLine2 != nil {
+		validator.Must(len(*v.AddressLine2) <= 255, "address_line2", "address_line2 must be at most 255 characters")
+	}
+	if v.City != nil {
+		validator.Must(len(*v.City) >= 1 && len(*v.City) <= 255, "city", "city must be between 1 and 255 characters")
+	}
+	if v.Region != nil {
+		validator.Must(len(*v.Region) <= 255, "region", "region must be at most 255 characters")
+	}
+	if v.PostalCode != nil {
+		validator.Must(len(*v.PostalCode) <= 32, "postal_code", "postal_code must be at most 32 characters")
+	}
+	if v.Country != nil {
+		validator.Regex(utils.NormalizeCode(*v.Country), countryPattern, "country", "country must be an ISO 3166-1 alpha-2 code")
+	}
+	if v.Latitude != nil {
+		validator.Must(*v.Latitude >= -90 && *v.Latitude <= 90, "latitude", "latitude must be between -90 and 90")
+	}
+	if v.Longitude != nil {
+		validator.Must(*v.Longitude >= -180 && *v.Longitude <= 180, "longitude", "longitude must be between -180 and 180")
+	}
+	if v.Timezone != nil {
+		validator.Must(validTimezone(*v.Timezone), "timezone", "timezone must be an IANA time zone name")
+	}
+	if v.MaxCapacity != nil {
+		validator.Must(*v.MaxCapacity > 0, "max_capacity", "max_capacity must be positive integer")
+	}
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+// VenueSearchQuery filters GET /venues, which lists venues by name.
+type VenueSearchQuery struct {
+	Q       string `form:"q"`
+	City    string `form:"city"`
+	Country string `form:"country"`
+}
+
+func (q *VenueSearchQuery) Validate() utils.ValidationErrors {
+	validator := utils.NewValidator()
+	validator.Must(len(q.Q) <= 200, "q", "q must be at most 200 characters")
+	validator.Must(len(q.City) <= 255, "city", "city must be at most 255 characters")
+	if q.Country != "" {
+		validator.Regex(utils.NormalizeCode(q.Country), countryPattern, "country", "country must be an ISO 3166-1 alpha-2 code")
+	}
+	if !validator.Valid() {
+		return validator.Errors
+	}
+	return nil
+}
+
+type Venue struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2,omitempty"`
+	City         string    `json:"city"`
+	Region       string    `json:"region,omitempty"`
+	PostalCode   string    `json:"postal_code,omitempty"`
+	Country      string    `json:"country"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Timezone     string    `json:"timezone"`
+	MaxCapacity  int       `json:"max_capacity"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func VenueEntityToVenue(v *entities.Venue) Venue {
+	return Venue{
+		ID:           v.ID,
+		Name:         v.Name,
+		AddressLine1: v.AddressLine1,
+		AddressLine2: v.AddressLine2.String,
+		City:         v.City,
+		Region:       v.Region.String,
+		PostalCode:   v.PostalCode.String,
+		Country:      v.Country,
+		Latitude:     v.Latitude,
+		Longitude:    v.Longitude,
+		Timezone:     v.Timezone,
+		MaxCapacity:  v.MaxCapacity,
+		CreatedAt:    v.CreatedAt,
+		UpdatedAt:    v.UpdatedAt,
+	}
+}
+
+func VenueEntitiesToVenues(venues []entities.Venue) []Venue {
+	result := make([]Venue, len(venues))
+	for i := range venues {
+		result[i] = VenueEntityToVenue(&venues[i])
+	}
+	return result
+}
+
+func validTimezone(name string) bool {
+	// LoadLocation takes "" and "Local" to mean the server's own zone
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
@@ -0,0 +1,39 @@
+package venues
+
+import (
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// earthRadiusKm is the mean radius of the earth.
+const earthRadiusKm = 6371.0
+
+// DistanceSQL is the great-circle distance in kilometers between a venue and
+// the point given by DistanceVars, by the haversine formula.
+// LEAST keeps rounding from pushing antipodal points out of ASIN's domain.
+const DistanceSQL = "2 * 6371 * ASIN(LEAST(1, SQRT(" +
+	"POWER(SIN(RADIANS(venues.latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(venues.latitude)) * POWER(SIN(RADIANS(venues.longitude - ?) / 2), 2))))"
+
+// DistanceVars are the arguments of DistanceSQL for the point lat, lng.
+func DistanceVars(lat, lng float64) []any {
+	return []any{lat, lat, lng}
+}
+
+// Within is a subquery of the ids of venues within radiusKm of lat, lng. A
+// bounding box narrows the candidates through the location index first.
+func Within(db *gorm.DB, lat, lng, radiusKm float64) *gorm.DB {
+	query := db.Table("venues").Select("venues.id")
+
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	query = query.Where("venues.latitude BETWEEN ? AND ?", lat-dLat, lat+dLat)
+	// near the poles or the antimeridian the box wraps, the distance alone decides
+	if math.Abs(lat)+dLat < 89 {
+		dLng := dLat / math.Cos(lat*math.Pi/180)
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			query = query.Where("venues.longitude BETWEEN ? AND ?", lng-dLng, lng+dLng)
+		}
+	}
+	return query.Where(DistanceSQL+" <= ?", append(DistanceVars(lat, lng), radiusKm)...)
+}
@@ -0,0 +1,181 @@
+package venues
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (service *VenuesService) CreateVenueHandler(c *gin.Context) {
+	var input VenueCreateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	venue := &entities.Venue{
+		UserId:       userId,
+		Name:         input.Name,
+		AddressLine1: input.AddressLine1,
+		City:         input.City,
+		Country:      utils.NormalizeCode(input.Country),
+		Latitude:     *input.Latitude,
+		Longitude:    *input.Longitude,
+		Timezone:     input.Timezone,
+		MaxCapacity:  input.MaxCapacity,
+	}
+	if input.AddressLine2 != nil {
+		venue.AddressLine2 = sql.NullString{String: *input.AddressLine2, Valid: *input.AddressLine2 != ""}
+	}
+	if input.Region != nil {
+		venue.Region = sql.NullString{String: *input.Region, Valid: *input.Region != ""}
+	}
+	if input.PostalCode != nil {
+		venue.PostalCode = sql.NullString{String: *input.PostalCode, Valid: *input.PostalCode != ""}
+	}
+
+	if err := service.createVenue(c.Request.Context(), venue); err != nil {
+		if errors.Is(err, ErrDuplicateVenue) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed creating venue", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusCreated, VenueEntityToVenue(venue))
+}
+
+func (service *VenuesService) GetVenueHandler(c *gin.Context) {
+	venueId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+		return
+	}
+
+	venue, err := service.getVenue(c.Request.Context(), venueId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+			return
+		}
+		service.logger.Error("failed getting venue", "venueId", venueId.String(), "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, VenueEntityToVenue(venue))
+}
+
+func (service *VenuesService) GetVenuesHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	var q VenueSearchQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := q.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	venues, page, err := service.getVenues(c.Request.Context(), &q, &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get venues", "limit", p.PageSize, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        VenueEntitiesToVenues(venues),
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
+
+func (service *VenuesService) UpdateVenueHandler(c *gin.Context) {
+	venueId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+		return
+	}
+
+	var input VenueUpdateDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	venue, err := service.updateVenue(c.Request.Context(), venueId, userId, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+		case errors.Is(err, ErrDuplicateVenue), errors.Is(err, ErrBelowAllocated):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed updating venue", "venueId", venueId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, VenueEntityToVenue(venue))
+}
+
+func (service *VenuesService) DeleteVenueHandler(c *gin.Context) {
+	venueId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+		return
+	}
+
+	if err := service.deleteVenue(c.Request.Context(), venueId); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "venue not found"})
+		case errors.Is(err, ErrVenueInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed deleting venue", "venueId", venueId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "venue deleted"})
+}
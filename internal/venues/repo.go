@@ -0,0 +1,169 @@
+package venues
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (service *VenuesService) createVenue(ctx context.Context, venue *entities.Venue) error {
+	venue.ID = uuid.New()
+	err := service.db.WithContext(ctx).Create(venue).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateVenue
+	}
+	return err
+}
+
+func (service *VenuesService) getVenue(ctx context.Context, venueId uuid.UUID) (*entities.Venue, error) {
+	venue, err := gorm.G[entities.Venue](service.db).Where("id = ?", venueId).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// getVenues lists the venues matching q by name.
+func (service *VenuesService) getVenues(ctx context.Context, q *VenueSearchQuery, p *utils.CursorPagination) ([]entities.Venue, utils.Page, error) {
+	db := service.db.WithContext(ctx).Model(&entities.Venue{})
+	if q.Q != "" {
+		pattern := "%" + utils.EscapeLike(q.Q) + "%"
+		db = db.Where("venues.name ILIKE ? OR venues.address_line1 ILIKE ?", pattern, pattern)
+	}
+	if q.City != "" {
+		db = db.Where("venues.city ILIKE ?", utils.EscapeLike(q.City))
+	}
+	if q.Country != "" {
+		db = db.Where("venues.country = ?", utils.NormalizeCode(q.Country))
+	}
+
+	var venues []entities.Venue
+	keyset := utils.Keyset{Name: "name", Table: "venues", Column: "venues.name"}
+	page, err := utils.CursorPaginate(db, p, keyset, func(v *entities.Venue) uuid.UUID { return v.ID }, &venues)
+	if err != nil {
+		return nil, page, err
+	}
+	return venues, page, nil
+}
+
+// updateVenue applies input to a venue. Events at the venue follow a new
+// name, which goes in their history as a change by userId.
+func (service *VenuesService) updateVenue(ctx context.Context, venueId, userId uuid.UUID, input VenueUpdateDTO) (*entities.Venue, error) {
+	var venue entities.Venue
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", venueId).First(&venue).Error; err != nil {
+			return err
+		}
+
+		renamed := input.Name != nil && *input.Name != venue.Name
+		if input.Name != nil {
+			venue.Name = *input.Name
+		}
+		if input.AddressLine1 != nil {
+			venue.AddressLine1 = *input.AddressLine1
+		}
+		if input.AddressLine2 != nil {
+			venue.AddressLine2 = sql.NullString{String: *input.AddressLine2, Valid: *input.AddressLine2 != ""}
+		}
+		if input.City != nil {
+			venue.City = *input.City
+		}
+		if input.Region != nil {
+			venue.Region = sql.NullString{String: *input.Region, Valid: *input.Region != ""}
+		}
+		if input.PostalCode != nil {
+			venue.PostalCode = sql.NullString{String: *input.PostalCode, Valid: *input.PostalCode != ""}
+		}
+		if input.Country != nil {
+			venue.Country = utils.NormalizeCode(*input.Country)
+		}
+		if input.Latitude != nil {
+			venue.Latitude = *input.Latitude
+		}
+		if input.Longitude != nil {
+			venue.Longitude = *input.Longitude
+		}
+		if input.Timezone != nil {
+			venue.Timezone = *input.Timezone
+		}
+		if input.MaxCapacity != nil && *input.MaxCapacity < venue.MaxCapacity {
+			// canceled and completed events no longer need their seats, the
+			// others need their units issued or their capacity, whichever is more
+			var allocated int
+			err := tx.Raw(`SELECT COALESCE(MAX(total), 0) FROM (
+				SELECT GREATEST(events.capacity, COALESCE(SUM(tickets.total_quantities), 0)) AS total
+				FROM events LEFT JOIN tickets ON tickets.event_id = events.id
+				WHERE events.venue_id = ? AND events.status NOT IN ?
+				GROUP BY events.id
+			) allocations`, venue.ID, []string{entities.EventCanceled, entities.EventCompleted}).Scan(&allocated).Error
+			if err != nil {
+				return err
+			}
+			if *input.MaxCapacity < allocated {
+				return ErrBelowAllocated
+			}
+		}
+		if input.MaxCapacity != nil {
+			venue.MaxCapacity = *input.MaxCapacity
+		}
+
+		if err := tx.Save(&venue).Error; err != nil {
+			return err
+		}
+		if !renamed {
+			return nil
+		}
+		var events []entities.Event
+		if err := tx.Select("id", "venue", "status").Where("venue_id = ?", venue.ID).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := tx.Model(&entities.Event{}).Where("venue_id = ?", venue.ID).Update("venue", venue.Name).Error; err != nil {
+			return err
+		}
+		changes := make([]entities.EventChange, len(events))
+		for i, event := range events {
+			// events that are over have no one left to tell
+			closed := event.Status == entities.EventCanceled || event.Status == entities.EventCompleted
+			changes[i] = entities.EventChange{
+				ID:       uuid.New(),
+				EventId:  event.ID,
+				UserId:   uuid.NullUUID{UUID: userId, Valid: true},
+				Field:    "venue",
+				OldValue: event.Venue,
+				NewValue: venue.Name,
+				Notify:   !closed,
+			}
+		}
+		return tx.Create(&changes).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrDuplicateVenue
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+func (service *VenuesService) deleteVenue(ctx context.Context, venueId uuid.UUID) error {
+	rowsAffected, err := gorm.G[entities.Venue](service.db).Where("id = ?", venueId).Delete(ctx)
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return ErrVenueInUse
+	}
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
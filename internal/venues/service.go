@@ -0,0 +1,28 @@
+package venues
+
+import (
+	"errors"
+	"log/slog"
+
+	// venue timezones are checked against the embedded IANA database, so
+	// hosts without one accept the same names
+	_ "time/tzdata"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDuplicateVenue = errors.New("a venue with this name and address already exists")
+	ErrVenueInUse     = errors.New("venue is used by events")
+	ErrOverCapacity   = errors.New("ticket quantities or event capacity exceed the venue's capacity")
+	ErrBelowAllocated = errors.New("max_capacity is below the ticket quantities of events at the venue")
+)
+
+type VenuesService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewVenuesService(db *gorm.DB, logger *slog.Logger) *VenuesService {
+	return &VenuesService{db: db, logger: logger}
+}
@@ -0,0 +1,55 @@
+package waitlist
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+)
+
+type WaitlistJoinDTO struct {
+	Quantity int `json:"quantity" binding:"required"`
+	// AccessCode unlocks a hidden ticket
+	AccessCode *string `json:"access_code"`
+}
+
+func (w *WaitlistJoinDTO) Validate() utils.ValidationErrors {
+	v := utils.NewValidator()
+	v.Must(w.Quantity > 0, "quantity", "must be positive integer")
+	if !v.Valid() {
+		return v.Errors
+	}
+	return nil
+}
+
+type WaitlistEntry struct {
+	ID       uuid.UUID `json:"id"`
+	TicketId uuid.UUID `json:"ticket_id"`
+	UserId   uuid.UUID `json:"user_id"`
+	Quantity int       `json:"quantity"`
+	Status   string    `json:"status"`
+	Position *int64    `json:"position,omitempty"` // entries ahead, while waiting
+	// the offer, pay for it with POST /payments before it expires
+	OrderId        *uuid.UUID `json:"order_id,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func WaitlistEntryToWaitlistEntry(e *entities.WaitlistEntry) WaitlistEntry {
+	entry := WaitlistEntry{
+		ID:        e.ID,
+		TicketId:  e.TicketId,
+		UserId:    e.UserId,
+		Quantity:  e.Quantity,
+		Status:    e.Status,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.OrderId.Valid {
+		entry.OrderId = &e.OrderId.UUID
+	}
+	if e.Order != nil && e.Status == entities.WaitlistOffered {
+		entry.OfferExpiresAt = &e.Order.ExpiresAt
+	}
+	return entry
+}
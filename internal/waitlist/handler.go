@@ -0,0 +1,138 @@
+package waitlist
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/accesscodes"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (service *WaitlistService) JoinWaitlistHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+
+	var input WaitlistJoinDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors := input.Validate(); errors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	entry, err := service.joinWaitlist(c.Request.Context(), userId, ticketId, input.Quantity, input.AccessCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		case errors.Is(err, accesscodes.ErrAccessCodeRequired), errors.Is(err, accesscodes.ErrInvalidAccessCode):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrNotOnSale), errors.Is(err, ErrInvalidQuantity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTicketsAvailable), errors.Is(err, ErrAlreadyWaitlisted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed joining waitlist", "ticketId", ticketId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	response := WaitlistEntryToWaitlistEntry(entry)
+	if ahead, err := service.position(c.Request.Context(), entry); err == nil {
+		response.Position = &ahead
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (service *WaitlistService) LeaveWaitlistHandler(c *gin.Context) {
+	ticketId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := service.leaveWaitlist(c.Request.Context(), userId, ticketId); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not on the waitlist"})
+		case errors.Is(err, ErrOfferPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			service.logger.Error("failed leaving waitlist", "ticketId", ticketId.String(), "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (service *WaitlistService) GetMyWaitlistHandler(c *gin.Context) {
+	var p utils.CursorPagination
+	if err := c.ShouldBindQuery(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		return
+	}
+
+	userIdAny, _ := c.Get("user_id")
+	userId, ok := userIdAny.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	entries, page, err := service.getUserEntries(c.Request.Context(), userId, &p)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.logger.Error("failed to get waitlist entries", "userId", userId.String(), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	data := make([]WaitlistEntry, len(entries))
+	for i := range entries {
+		data[i] = WaitlistEntryToWaitlistEntry(&entries[i])
+		if entries[i].Status == entities.WaitlistWaiting {
+			ahead, err := service.position(c.Request.Context(), &entries[i])
+			if err != nil {
+				service.logger.Error("failed to get waitlist position", "entryId", entries[i].ID.String(), "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+				return
+			}
+			data[i].Position = &ahead
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        data,
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   page.PageSize,
+	})
+}
@@ -0,0 +1,117 @@
+package waitlist
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/limits"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OfferReleased offers units of ticket that were just handed back to its
+// RemainingQuantities to the waitlist, oldest entry first. Each offer is a
+// pending order holding the entry's quantity, so nobody else can buy the units
+// while the user decides; when the order expires or is canceled the units come
+// back here and go to the next entry. Entries asking for more than is left,
+// or for more than their user may still buy, are skipped until that changes.
+//
+// ticket must be locked by tx; the caller saves it afterwards.
+func OfferReleased(tx *gorm.DB, ticket *entities.Ticket) error {
+	now := time.Now()
+	if ticket.RemainingQuantities <= 0 || !ticket.OnSale(now) {
+		return nil
+	}
+	// units released by canceling or postponing an event are not offered
+	var event entities.Event
+	if err := tx.Select("id", "status", "capacity").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+		return err
+	}
+	if !event.OnSale() {
+		return nil
+	}
+	// room is how many more units the event's capacity allows holding
+	room := ticket.RemainingQuantities
+	if event.Capacity > 0 {
+		var taken int
+		err := tx.Model(&entities.Ticket{}).
+			Select("COALESCE(SUM(total_quantities - remaining_quantities), 0)").
+			Where("event_id = ? AND id <> ?", event.ID, ticket.ID).
+			Scan(&taken).Error
+		if err != nil {
+			return err
+		}
+		taken += ticket.TotalQuantities - ticket.RemainingQuantities
+		room = min(room, event.Capacity-taken)
+		if room <= 0 {
+			return nil
+		}
+	}
+
+	var entries []entities.WaitlistEntry
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ticket_id = ? AND status = ? AND quantity <= ?", ticket.ID, entities.WaitlistWaiting, room).
+		Order("created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Quantity > room {
+			continue
+		}
+		// an offer the user can't pay for would hold the units for nothing
+		err := limits.Check(tx, entry.UserId, ticket, entry.Quantity, uuid.Nil)
+		if errors.Is(err, limits.ErrPurchaseLimitExceeded) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		order := entities.Order{
+			ID:        uuid.New(),
+			UserId:    entry.UserId,
+			TicketId:  ticket.ID,
+			Quantity:  entry.Quantity,
+			Amount:    int64(entry.Quantity) * ticket.Price,
+			Currency:  ticket.Currency,
+			Status:    entities.OrderPending,
+			ExpiresAt: now.Add(offerWindow()),
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		ticket.RemainingQuantities -= entry.Quantity
+		ticket.ReservedQuantities += entry.Quantity
+		room -= entry.Quantity
+
+		entry.Status = entities.WaitlistOffered
+		entry.OrderId = uuid.NullUUID{UUID: order.ID, Valid: true}
+		entry.OfferedAt = sql.NullTime{Time: now, Valid: true}
+		if err := tx.Model(entry).Select("status", "order_id", "offered_at").Updates(entry).Error; err != nil {
+			return err
+		}
+		if room == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// CloseOffer records how an order ended if it was a waitlist offer: purchased
+// when the order was confirmed, lapsed otherwise.
+func CloseOffer(tx *gorm.DB, orderId uuid.UUID, orderStatus string) error {
+	status := entities.WaitlistLapsed
+	if orderStatus == entities.OrderConfirmed {
+		status = entities.WaitlistPurchased
+	}
+	return tx.Model(&entities.WaitlistEntry{}).
+		Where("order_id = ? AND status = ?", orderId, entities.WaitlistOffered).
+		Update("status", status).Error
+}
@@ -0,0 +1,117 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/tickr/internal/accesscodes"
+	"github.com/rezbow/tickr/internal/entities"
+	"github.com/rezbow/tickr/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// joinWaitlist queues the user for quantity units of a ticket that cannot
+// currently cover them. Hidden tickets need accessCode, as offers are ordered
+// without one.
+func (service *WaitlistService) joinWaitlist(ctx context.Context, userId, ticketId uuid.UUID, quantity int, accessCode *string) (*entities.WaitlistEntry, error) {
+	var entry entities.WaitlistEntry
+	err := service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ticket entities.Ticket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ticketId).First(&ticket).Error; err != nil {
+			return err
+		}
+		if _, err := accesscodes.Unlock(tx, &ticket, accessCode); err != nil {
+			return err
+		}
+		if !ticket.OnSale(time.Now()) {
+			return ErrNotOnSale
+		}
+		var event entities.Event
+		if err := tx.Select("id", "status").Where("id = ?", ticket.EventId).First(&event).Error; err != nil {
+			return err
+		}
+		if !event.OnSale() {
+			return ErrNotOnSale
+		}
+		if quantity < ticket.MinPerOrder || (ticket.MaxPerOrder > 0 && quantity > ticket.MaxPerOrder) {
+			return ErrInvalidQuantity
+		}
+		if ticket.RemainingQuantities >= quantity {
+			return ErrTicketsAvailable
+		}
+
+		var active int64
+		err := tx.Model(&entities.WaitlistEntry{}).
+			Where("ticket_id = ? AND user_id = ? AND status IN ?", ticketId, userId, []string{entities.WaitlistWaiting, entities.WaitlistOffered}).
+			Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrAlreadyWaitlisted
+		}
+
+		entry = entities.WaitlistEntry{
+			ID:       uuid.New(),
+			TicketId: ticketId,
+			UserId:   userId,
+			Quantity: quantity,
+			Status:   entities.WaitlistWaiting,
+		}
+		return tx.Create(&entry).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrAlreadyWaitlisted
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// leaveWaitlist takes the user's waiting entry for a ticket off the waitlist.
+func (service *WaitlistService) leaveWaitlist(ctx context.Context, userId, ticketId uuid.UUID) error {
+	var entry entities.WaitlistEntry
+	err := service.db.WithContext(ctx).
+		Where("ticket_id = ? AND user_id = ? AND status IN ?", ticketId, userId, []string{entities.WaitlistWaiting, entities.WaitlistOffered}).
+		First(&entry).Error
+	if err != nil {
+		return err
+	}
+	if entry.Status == entities.WaitlistOffered {
+		return ErrOfferPending
+	}
+
+	res := service.db.WithContext(ctx).Model(&entry).Where("status = ?", entities.WaitlistWaiting).Update("status", entities.WaitlistLeft)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// offered between the read and the update
+		return ErrOfferPending
+	}
+	return nil
+}
+
+// position is how many entries are ahead of a waiting entry.
+func (service *WaitlistService) position(ctx context.Context, entry *entities.WaitlistEntry) (int64, error) {
+	var ahead int64
+	err := service.db.WithContext(ctx).Model(&entities.WaitlistEntry{}).
+		Where("ticket_id = ? AND status = ? AND created_at < ?", entry.TicketId, entities.WaitlistWaiting, entry.CreatedAt).
+		Count(&ahead).Error
+	return ahead, err
+}
+
+// getUserEntries pages through a user's waitlist entries, newest first.
+func (service *WaitlistService) getUserEntries(ctx context.Context, userId uuid.UUID, p *utils.CursorPagination) ([]entities.WaitlistEntry, utils.Page, error) {
+	var entries []entities.WaitlistEntry
+	keyset := utils.Keyset{Name: "created_at", Table: "waitlist_entries", Column: "waitlist_entries.created_at", Desc: true}
+	page, err := utils.CursorPaginate(service.db.WithContext(ctx).Preload("Order").Where("user_id = ?", userId), p, keyset, func(e *entities.WaitlistEntry) uuid.UUID { return e.ID }, &entries)
+	if err != nil {
+		return nil, page, err
+	}
+	return entries, page, nil
+}
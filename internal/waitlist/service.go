@@ -0,0 +1,40 @@
+package waitlist
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultOfferWindow = 30 * time.Minute
+
+var (
+	ErrTicketsAvailable  = errors.New("tickets are still available, buy them directly")
+	ErrAlreadyWaitlisted = errors.New("already on the waitlist for this ticket")
+	ErrNotOnSale         = errors.New("ticket is not on sale")
+	ErrInvalidQuantity   = errors.New("quantity outside the ticket's per-order limits")
+	ErrOfferPending      = errors.New("an offer is pending, cancel its order instead")
+)
+
+type WaitlistService struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewWaitlistService(db *gorm.DB, logger *slog.Logger) *WaitlistService {
+	return &WaitlistService{db: db, logger: logger}
+}
+
+// offerWindow is how long a waitlisted user has to pay for an offer.
+// WAITLIST_OFFER_WINDOW overrides the default.
+func offerWindow() time.Duration {
+	if value := os.Getenv("WAITLIST_OFFER_WINDOW"); value != "" {
+		if window, err := time.ParseDuration(value); err == nil && window > 0 {
+			return window
+		}
+	}
+	return defaultOfferWindow
+}
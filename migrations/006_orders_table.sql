@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE orders (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	quantity INT NOT NULL,
+	amount BIGINT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_orders_status_expires_at ON orders(status, expires_at);
+
+ALTER TABLE tickets ADD COLUMN reserved_quantities INT NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN order_id UUID REFERENCES orders(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE payment DROP COLUMN IF EXISTS order_id;
+ALTER TABLE tickets DROP COLUMN IF EXISTS reserved_quantities;
+DROP TABLE IF EXISTS orders;
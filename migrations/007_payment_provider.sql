@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE payment ADD COLUMN provider VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE payment ADD COLUMN provider_ref VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_payment_order_id ON payment(order_id);
+CREATE INDEX idx_payment_provider_ref ON payment(provider, provider_ref);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payment_provider_ref;
+DROP INDEX IF EXISTS idx_payment_order_id;
+ALTER TABLE payment DROP COLUMN IF EXISTS provider_ref;
+ALTER TABLE payment DROP COLUMN IF EXISTS provider;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE refunds (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	quantity INT NOT NULL,
+	amount BIGINT NOT NULL,
+	reason TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+
+ALTER TABLE payment ADD COLUMN refunded_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN refund_policy VARCHAR(20) NOT NULL DEFAULT 'before_start' check (refund_policy in ('none', 'before_start', 'anytime'));
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN IF EXISTS refund_policy;
+ALTER TABLE payment DROP COLUMN IF EXISTS refunded_amount;
+ALTER TABLE payment DROP COLUMN IF EXISTS refunded_quantity;
+DROP TABLE IF EXISTS refunds;
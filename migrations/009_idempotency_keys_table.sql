@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	key VARCHAR(255) NOT NULL,
+	scope VARCHAR(255) NOT NULL,
+	request_hash VARCHAR(64) NOT NULL,
+	response_status INT NOT NULL DEFAULT 0,
+	response_content_type VARCHAR(255) NOT NULL DEFAULT '',
+	response_body BYTEA,
+	completed_at TIMESTAMP,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
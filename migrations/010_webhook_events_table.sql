@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE webhook_events (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	provider VARCHAR(50) NOT NULL,
+	provider_event_id VARCHAR(255) NOT NULL,
+	type VARCHAR(100) NOT NULL,
+	payment_id UUID REFERENCES payment(id) ON DELETE SET NULL,
+	payload BYTEA NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'received',
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (provider, provider_event_id)
+);
+
+-- the provider's id of each refund, so webhooks echoing our own refunds are recognised
+ALTER TABLE refunds ADD COLUMN provider_ref VARCHAR(255) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX idx_refunds_provider_ref ON refunds(provider_ref) WHERE provider_ref <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refunds_provider_ref;
+ALTER TABLE refunds DROP COLUMN IF EXISTS provider_ref;
+DROP TABLE IF EXISTS webhook_events;
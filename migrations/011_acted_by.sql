@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE orders ADD COLUMN acted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE payment ADD COLUMN acted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_payment_user_id ON payment(user_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payment_user_id;
+ALTER TABLE payment DROP COLUMN IF EXISTS acted_by;
+ALTER TABLE orders DROP COLUMN IF EXISTS acted_by;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE tickets ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT 'General Admission';
+ALTER TABLE tickets ADD COLUMN description TEXT;
+ALTER TABLE tickets ADD COLUMN sales_start TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN sales_end TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN min_per_order INT NOT NULL DEFAULT 1;
+ALTER TABLE tickets ADD COLUMN max_per_order INT NOT NULL DEFAULT 0;
+ALTER TABLE tickets ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public' check (visibility in ('public', 'hidden'));
+
+CREATE INDEX idx_tickets_event_id ON tickets(event_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tickets_event_id;
+ALTER TABLE tickets DROP COLUMN IF EXISTS visibility;
+ALTER TABLE tickets DROP COLUMN IF EXISTS max_per_order;
+ALTER TABLE tickets DROP COLUMN IF EXISTS min_per_order;
+ALTER TABLE tickets DROP COLUMN IF EXISTS sales_end;
+ALTER TABLE tickets DROP COLUMN IF EXISTS sales_start;
+ALTER TABLE tickets DROP COLUMN IF EXISTS description;
+ALTER TABLE tickets DROP COLUMN IF EXISTS name;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE tickets ADD COLUMN max_per_user INT NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN max_tickets_per_user INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_orders_user_id ON orders(user_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_orders_user_id;
+ALTER TABLE events DROP COLUMN IF EXISTS max_tickets_per_user;
+ALTER TABLE tickets DROP COLUMN IF EXISTS max_per_user;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE passes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	code VARCHAR(64) NOT NULL UNIQUE,
+	status VARCHAR(20) NOT NULL DEFAULT 'valid' check (status in ('valid', 'used', 'void')),
+	checked_in_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_passes_payment_id ON passes(payment_id);
+CREATE INDEX idx_passes_user_id ON passes(user_id);
+CREATE INDEX idx_passes_event_id ON passes(event_id, status);
+
+-- payments confirmed before passes existed get one for each unit not refunded.
+-- their codes are hex rather than base32, check-in treats codes as opaque
+INSERT INTO passes (payment_id, ticket_id, event_id, user_id, code)
+SELECT payment.id, payment.ticket_id, tickets.event_id, payment.user_id,
+	UPPER(SUBSTRING(REPLACE(uuid_generate_v4()::TEXT || uuid_generate_v4()::TEXT, '-', '') FOR 40))
+FROM payment
+JOIN tickets ON tickets.id = payment.ticket_id
+CROSS JOIN LATERAL generate_series(1, payment.quantity - payment.refunded_quantity)
+WHERE payment.status IN ('confirmed', 'partially_refunded');
+
+-- +goose Down
+DROP TABLE IF EXISTS passes;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE event_staffs (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (event_id, user_id)
+);
+
+CREATE TABLE checkin_scans (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+	pass_id UUID REFERENCES passes(id) ON DELETE SET NULL,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	action VARCHAR(20) NOT NULL,
+	result VARCHAR(20) NOT NULL,
+	scanned_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_checkin_scans_event_id ON checkin_scans(event_id, scanned_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS checkin_scans;
+DROP TABLE IF EXISTS event_staffs;
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE checkin_scans ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'online';
+ALTER TABLE checkin_scans ADD COLUMN device_id VARCHAR(100) NOT NULL DEFAULT '';
+-- offline scans carry an id from their device, so a retried sync applies each once
+ALTER TABLE checkin_scans ADD COLUMN scan_id VARCHAR(100) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX idx_checkin_scans_device_scan ON checkin_scans(event_id, device_id, scan_id) WHERE scan_id <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_checkin_scans_device_scan;
+ALTER TABLE checkin_scans DROP COLUMN IF EXISTS scan_id;
+ALTER TABLE checkin_scans DROP COLUMN IF EXISTS device_id;
+ALTER TABLE checkin_scans DROP COLUMN IF EXISTS source;
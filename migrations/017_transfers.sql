@@ -0,0 +1,44 @@
+-- +goose Up
+ALTER TABLE tickets ADD COLUMN transfers_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE transfers (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	recipient_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	recipient_email VARCHAR(255) NOT NULL,
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	quantity INT NOT NULL check (quantity > 0),
+	status VARCHAR(20) NOT NULL DEFAULT 'pending' check (status in ('pending', 'accepted', 'canceled')),
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	expires_at TIMESTAMP NOT NULL,
+	accepted_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transfers_user_id ON transfers(user_id);
+CREATE INDEX idx_transfers_recipient_id ON transfers(recipient_id);
+CREATE INDEX idx_transfers_recipient_email ON transfers(LOWER(recipient_email));
+
+CREATE TABLE transfer_passes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	transfer_id UUID REFERENCES transfers(id) ON DELETE CASCADE,
+	pass_id UUID REFERENCES passes(id) ON DELETE CASCADE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transfer_passes_transfer_id ON transfer_passes(transfer_id);
+CREATE INDEX idx_transfer_passes_pass_id ON transfer_passes(pass_id);
+
+-- accepted transfers split the recipient's units off the sender's payment
+ALTER TABLE payment ADD COLUMN transferred_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN transferred_from UUID REFERENCES payment(id) ON DELETE SET NULL;
+CREATE INDEX idx_payment_transferred_from ON payment(transferred_from);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payment_transferred_from;
+ALTER TABLE payment DROP COLUMN IF EXISTS transferred_from;
+ALTER TABLE payment DROP COLUMN IF EXISTS transferred_quantity;
+DROP TABLE IF EXISTS transfer_passes;
+DROP TABLE IF EXISTS transfers;
+ALTER TABLE tickets DROP COLUMN IF EXISTS transfers_enabled;
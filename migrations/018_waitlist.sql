@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE waitlist_entries (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	quantity INT NOT NULL check (quantity > 0),
+	status VARCHAR(20) NOT NULL DEFAULT 'waiting' check (status in ('waiting', 'offered', 'purchased', 'lapsed', 'left')),
+	order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+	offered_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_waitlist_entries_ticket_id ON waitlist_entries(ticket_id, status, created_at);
+CREATE INDEX idx_waitlist_entries_order_id ON waitlist_entries(order_id);
+CREATE UNIQUE INDEX idx_waitlist_entries_active ON waitlist_entries(ticket_id, user_id) WHERE status IN ('waiting', 'offered');
+
+-- +goose Down
+DROP TABLE IF EXISTS waitlist_entries;
@@ -0,0 +1,61 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN resale_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE events ADD COLUMN resale_max_markup INT NOT NULL DEFAULT 0 check (resale_max_markup >= 0);
+ALTER TABLE payment ADD COLUMN resold_quantity INT NOT NULL DEFAULT 0;
+
+CREATE TABLE listings (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+	quantity INT NOT NULL check (quantity > 0),
+	reserved_quantity INT NOT NULL DEFAULT 0,
+	sold_quantity INT NOT NULL DEFAULT 0,
+	price BIGINT NOT NULL check (price > 0),
+	status VARCHAR(20) NOT NULL DEFAULT 'active' check (status in ('active', 'sold', 'canceled')),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	check (reserved_quantity + sold_quantity <= quantity)
+);
+
+CREATE INDEX idx_listings_event_id ON listings(event_id, status, price);
+CREATE INDEX idx_listings_user_id ON listings(user_id);
+CREATE INDEX idx_listings_payment_id ON listings(payment_id, status);
+
+CREATE TABLE listing_passes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	listing_id UUID REFERENCES listings(id) ON DELETE CASCADE,
+	pass_id UUID REFERENCES passes(id) ON DELETE CASCADE,
+	sold BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_listing_passes_listing_id ON listing_passes(listing_id);
+CREATE INDEX idx_listing_passes_pass_id ON listing_passes(pass_id);
+
+CREATE TABLE resale_sales (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	listing_id UUID REFERENCES listings(id) ON DELETE CASCADE,
+	payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+	seller_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	buyer_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	quantity INT NOT NULL,
+	amount BIGINT NOT NULL,
+	fee BIGINT NOT NULL,
+	proceeds BIGINT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_resale_sales_seller_id ON resale_sales(seller_id);
+
+ALTER TABLE orders ADD COLUMN listing_id UUID REFERENCES listings(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE orders DROP COLUMN IF EXISTS listing_id;
+DROP TABLE IF EXISTS resale_sales;
+DROP TABLE IF EXISTS listing_passes;
+DROP TABLE IF EXISTS listings;
+ALTER TABLE payment DROP COLUMN IF EXISTS resold_quantity;
+ALTER TABLE events DROP COLUMN IF EXISTS resale_max_markup;
+ALTER TABLE events DROP COLUMN IF EXISTS resale_enabled;
@@ -0,0 +1,48 @@
+-- +goose Up
+CREATE TABLE discount_codes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	code VARCHAR(32) NOT NULL,
+	kind VARCHAR(20) NOT NULL check (kind in ('percent', 'fixed')),
+	value BIGINT NOT NULL check (value > 0),
+	max_uses INT NOT NULL DEFAULT 0,
+	max_uses_per_user INT NOT NULL DEFAULT 0,
+	used_count INT NOT NULL DEFAULT 0,
+	valid_from TIMESTAMP,
+	valid_until TIMESTAMP,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (event_id, code),
+	check (max_uses = 0 OR used_count <= max_uses)
+);
+
+CREATE TABLE discount_tickets (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	discount_code_id UUID REFERENCES discount_codes(id) ON DELETE CASCADE,
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	UNIQUE (discount_code_id, ticket_id)
+);
+
+CREATE TABLE discount_redemptions (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	discount_code_id UUID REFERENCES discount_codes(id) ON DELETE CASCADE,
+	payment_id UUID REFERENCES payment(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	amount BIGINT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_discount_redemptions_code_user ON discount_redemptions(discount_code_id, user_id);
+CREATE INDEX idx_discount_redemptions_payment_id ON discount_redemptions(payment_id);
+
+ALTER TABLE payment ADD COLUMN discount_code_id UUID REFERENCES discount_codes(id) ON DELETE SET NULL;
+ALTER TABLE payment ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE payment DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE payment DROP COLUMN IF EXISTS discount_code_id;
+DROP TABLE IF EXISTS discount_redemptions;
+DROP TABLE IF EXISTS discount_tickets;
+DROP TABLE IF EXISTS discount_codes;
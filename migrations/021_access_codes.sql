@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE access_codes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	ticket_id UUID REFERENCES tickets(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	code VARCHAR(64) NOT NULL,
+	valid_until TIMESTAMP,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (ticket_id, code)
+);
+
+CREATE INDEX idx_access_codes_code ON access_codes(code);
+
+ALTER TABLE orders ADD COLUMN access_code_id UUID REFERENCES access_codes(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE orders DROP COLUMN IF EXISTS access_code_id;
+DROP TABLE IF EXISTS access_codes;
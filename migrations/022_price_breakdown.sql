@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN fee_mode VARCHAR(20) NOT NULL DEFAULT 'pass_through' check (fee_mode in ('pass_through', 'absorbed'));
+ALTER TABLE events ADD COLUMN tax_rate INT NOT NULL DEFAULT 0 check (tax_rate >= 0 AND tax_rate <= 10000);
+
+ALTER TABLE payment ADD COLUMN face_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN fee_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN fee_absorbed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE payment ADD COLUMN tax_amount BIGINT NOT NULL DEFAULT 0;
+
+-- payments so far carried neither fees nor tax
+UPDATE payment SET face_amount = paid_amount + discount_amount;
+
+-- +goose Down
+ALTER TABLE payment DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE payment DROP COLUMN IF EXISTS fee_absorbed;
+ALTER TABLE payment DROP COLUMN IF EXISTS fee_amount;
+ALTER TABLE payment DROP COLUMN IF EXISTS face_amount;
+ALTER TABLE events DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE events DROP COLUMN IF EXISTS fee_mode;
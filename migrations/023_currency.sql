@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE tickets ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE orders ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE payment ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE listings ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE discount_codes ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- +goose Down
+ALTER TABLE discount_codes DROP COLUMN IF EXISTS currency;
+ALTER TABLE listings DROP COLUMN IF EXISTS currency;
+ALTER TABLE payment DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE tickets DROP COLUMN IF EXISTS currency;
+ALTER TABLE events DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE invoice_counters (
+	user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	last_number INT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE invoices (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	payment_id UUID UNIQUE REFERENCES payment(id) ON DELETE CASCADE,
+	organizer_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	number INT NOT NULL check (number > 0),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (organizer_id, number)
+);
+
+-- payments completed so far are numbered in the order they were made
+INSERT INTO invoices (payment_id, organizer_id, number, created_at)
+SELECT payment.id, events.user_id,
+	ROW_NUMBER() OVER (PARTITION BY events.user_id ORDER BY payment.created_at, payment.id),
+	payment.created_at
+FROM payment
+JOIN tickets ON tickets.id = payment.ticket_id
+JOIN events ON events.id = tickets.event_id
+WHERE payment.transferred_from IS NULL
+	AND (payment.status IN ('confirmed', 'partially_refunded', 'refunded')
+		OR (payment.status = 'canceled' AND payment.refunded_amount > 0));
+
+INSERT INTO invoice_counters (user_id, last_number)
+SELECT organizer_id, MAX(number) FROM invoices GROUP BY organizer_id;
+
+-- +goose Down
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_counters;
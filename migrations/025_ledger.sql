@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE payouts (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+	currency CHAR(3) NOT NULL,
+	amount BIGINT NOT NULL check (amount > 0),
+	entries INT NOT NULL,
+	period_end TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payouts_user_id ON payouts(user_id, created_at);
+
+CREATE TABLE ledger_entries (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	transaction_id UUID NOT NULL,
+	kind VARCHAR(20) NOT NULL check (kind in ('sale', 'refund', 'payout')),
+	account VARCHAR(20) NOT NULL check (account in ('cash', 'payable', 'revenue')),
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	event_id UUID REFERENCES events(id) ON DELETE SET NULL,
+	payment_id UUID REFERENCES payment(id) ON DELETE SET NULL,
+	refund_id UUID REFERENCES refunds(id) ON DELETE SET NULL,
+	payout_id UUID REFERENCES payouts(id) ON DELETE SET NULL,
+	amount BIGINT NOT NULL,
+	currency CHAR(3) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX idx_ledger_entries_payment_id ON ledger_entries(payment_id);
+CREATE INDEX idx_ledger_entries_unsettled ON ledger_entries(user_id, currency, created_at) WHERE account = 'payable' AND payout_id IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS payouts;
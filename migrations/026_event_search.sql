@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(venue, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_events_search ON events USING GIN (search);
+CREATE INDEX idx_events_start_time ON events(start_time);
+CREATE INDEX idx_events_user_id ON events(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_user_id;
+DROP INDEX IF EXISTS idx_events_start_time;
+DROP INDEX IF EXISTS idx_events_search;
+ALTER TABLE events DROP COLUMN IF EXISTS search;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE INDEX idx_events_start_time_id ON events(start_time, id);
+CREATE INDEX idx_events_created_at_id ON events(created_at, id);
+CREATE INDEX idx_users_created_at_id ON users(created_at, id);
+CREATE INDEX idx_tickets_event_id_price_id ON tickets(event_id, price, id);
+CREATE INDEX idx_payment_user_id_created_at_id ON payment(user_id, created_at, id);
+CREATE INDEX idx_passes_user_id_created_at_id ON passes(user_id, created_at, id);
+CREATE INDEX idx_waitlist_entries_user_id_created_at_id ON waitlist_entries(user_id, created_at, id);
+CREATE INDEX idx_listings_user_id_created_at_id ON listings(user_id, created_at, id);
+CREATE INDEX idx_listings_event_id_price_id ON listings(event_id, status, price, id);
+CREATE INDEX idx_payouts_user_id_created_at_id ON payouts(user_id, created_at, id);
+DROP INDEX IF EXISTS idx_events_start_time;
+DROP INDEX IF EXISTS idx_passes_user_id;
+DROP INDEX IF EXISTS idx_listings_user_id;
+DROP INDEX IF EXISTS idx_listings_event_id;
+DROP INDEX IF EXISTS idx_payouts_user_id;
+
+-- +goose Down
+CREATE INDEX idx_payouts_user_id ON payouts(user_id, created_at);
+CREATE INDEX idx_listings_event_id ON listings(event_id, status, price);
+CREATE INDEX idx_listings_user_id ON listings(user_id);
+CREATE INDEX idx_passes_user_id ON passes(user_id);
+CREATE INDEX idx_events_start_time ON events(start_time);
+DROP INDEX IF EXISTS idx_payouts_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_listings_event_id_price_id;
+DROP INDEX IF EXISTS idx_listings_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_waitlist_entries_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_passes_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_payment_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_tickets_event_id_price_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
+DROP INDEX IF EXISTS idx_events_created_at_id;
+DROP INDEX IF EXISTS idx_events_start_time_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- events created before the lifecycle existed were visible, so they start published
+ALTER TABLE events ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'published'
+	CHECK (status IN ('draft', 'published', 'postponed', 'canceled', 'completed'));
+ALTER TABLE events ALTER COLUMN status SET DEFAULT 'draft';
+CREATE INDEX idx_events_status ON events(status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_status;
+ALTER TABLE events DROP COLUMN IF EXISTS status;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN capacity INT NOT NULL DEFAULT 0 CHECK (capacity >= 0);
+
+CREATE TABLE event_changes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	field VARCHAR(64) NOT NULL,
+	old_value TEXT NOT NULL,
+	new_value TEXT NOT NULL,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_changes_event_id ON event_changes(event_id, created_at, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_changes;
+ALTER TABLE events DROP COLUMN IF EXISTS capacity;
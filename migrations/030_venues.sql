@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE venues (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID NOT NULL REFERENCES users(id),
+	name VARCHAR(255) NOT NULL,
+	address_line1 VARCHAR(255) NOT NULL,
+	address_line2 VARCHAR(255),
+	city VARCHAR(255) NOT NULL,
+	region VARCHAR(255),
+	postal_code VARCHAR(32),
+	country CHAR(2) NOT NULL,
+	latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+	longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+	timezone VARCHAR(64) NOT NULL,
+	max_capacity INT NOT NULL CHECK (max_capacity > 0),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- the same place added twice is one venue
+CREATE UNIQUE INDEX idx_venues_address ON venues(lower(name), lower(address_line1), lower(city), country);
+CREATE INDEX idx_venues_location ON venues(latitude, longitude);
+
+ALTER TABLE events ADD COLUMN venue_id UUID REFERENCES venues(id);
+CREATE INDEX idx_events_venue_id ON events(venue_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_venue_id;
+ALTER TABLE events DROP COLUMN IF EXISTS venue_id;
+DROP TABLE IF EXISTS venues;